@@ -2,12 +2,15 @@ package ast
 
 import (
 	"go-interpreter/token"
+	"math/big"
 	"strings"
 )
 
 type Node interface {
 	TokenLiteral() string
 	String() string
+	Pos() token.Position // position of the first character belonging to the node
+	End() token.Position // position of the first character immediately after the node
 }
 
 type Statement interface {
@@ -31,6 +34,20 @@ func (p *Program) TokenLiteral() string {
 	return ""
 }
 
+func (p *Program) Pos() token.Position {
+	if len(p.Stmts) > 0 {
+		return p.Stmts[0].Pos()
+	}
+	return token.Position{}
+}
+
+func (p *Program) End() token.Position {
+	if n := len(p.Stmts); n > 0 {
+		return p.Stmts[n-1].End()
+	}
+	return token.Position{}
+}
+
 func (p *Program) String() string {
 	var b strings.Builder
 	for _, s := range p.Stmts {
@@ -47,6 +64,8 @@ type LetStatement struct {
 
 func (ls *LetStatement) statementNode()       {}
 func (ls *LetStatement) TokenLiteral() string { return ls.Token.Literal }
+func (ls *LetStatement) Pos() token.Position  { return ls.Token.Pos }
+func (ls *LetStatement) End() token.Position  { return ls.Value.End() }
 func (ls *LetStatement) String() string {
 	var b strings.Builder
 	b.WriteString(ls.TokenLiteral() + " ")
@@ -55,6 +74,18 @@ func (ls *LetStatement) String() string {
 	return b.String()
 }
 
+// FunctionDeclaration is a 'fn name() {}' statement, binding the function to
+// its name in the enclosing scope before any statement of it runs.
+type FunctionDeclaration struct {
+	Func *FunctionLiteral
+}
+
+func (fd *FunctionDeclaration) statementNode()       {}
+func (fd *FunctionDeclaration) TokenLiteral() string { return fd.Func.TokenLiteral() }
+func (fd *FunctionDeclaration) Pos() token.Position  { return fd.Func.Pos() }
+func (fd *FunctionDeclaration) End() token.Position  { return fd.Func.End() }
+func (fd *FunctionDeclaration) String() string       { return fd.Func.String() }
+
 type ReturnStatement struct {
 	Token       *token.Token // the 'return' token
 	ReturnValue Expression
@@ -62,6 +93,13 @@ type ReturnStatement struct {
 
 func (rs *ReturnStatement) statementNode()       {}
 func (rs *ReturnStatement) TokenLiteral() string { return rs.Token.Literal }
+func (rs *ReturnStatement) Pos() token.Position  { return rs.Token.Pos }
+func (rs *ReturnStatement) End() token.Position {
+	if rs.ReturnValue != nil {
+		return rs.ReturnValue.End()
+	}
+	return rs.Token.End
+}
 func (rs *ReturnStatement) String() string {
 	var b strings.Builder
 	b.WriteString(rs.TokenLiteral() + " ")
@@ -72,8 +110,32 @@ func (rs *ReturnStatement) String() string {
 	return b.String()
 }
 
+// BranchStatement is a 'break' or 'continue', optionally naming the loop it
+// applies to.
+type BranchStatement struct {
+	Token *token.Token // the 'break' or 'continue' token
+	Label *Identifier  // nil when applying to the innermost loop
+}
+
+func (bs *BranchStatement) statementNode()       {}
+func (bs *BranchStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BranchStatement) Pos() token.Position  { return bs.Token.Pos }
+func (bs *BranchStatement) End() token.Position {
+	if bs.Label != nil {
+		return bs.Label.End()
+	}
+	return bs.Token.End
+}
+func (bs *BranchStatement) String() string {
+	if bs.Label != nil {
+		return bs.TokenLiteral() + " " + bs.Label.String() + ";"
+	}
+	return bs.TokenLiteral() + ";"
+}
+
 type ForLoopStatement struct {
 	Token             *token.Token // The 'for' token
+	Label             *Identifier  // nil for an unlabeled loop
 	Init              Statement
 	Condition, Update Expression
 	Body              *BlockStatement
@@ -81,8 +143,18 @@ type ForLoopStatement struct {
 
 func (f *ForLoopStatement) statementNode()       {}
 func (f *ForLoopStatement) TokenLiteral() string { return f.Token.Literal }
+func (f *ForLoopStatement) Pos() token.Position {
+	if f.Label != nil {
+		return f.Label.Pos()
+	}
+	return f.Token.Pos
+}
+func (f *ForLoopStatement) End() token.Position { return f.Body.End() }
 func (f *ForLoopStatement) String() string {
 	var b strings.Builder
+	if f.Label != nil {
+		b.WriteString(f.Label.String() + ": ")
+	}
 	b.WriteString(f.TokenLiteral())
 	b.WriteString(" (")
 	if f.Init != nil {
@@ -102,6 +174,101 @@ func (f *ForLoopStatement) String() string {
 	return b.String()
 }
 
+// ForInStatement is 'for (value in iterable)' or 'for (key, value in iterable)'.
+type ForInStatement struct {
+	Token      *token.Token // The 'for' token
+	Label      *Identifier  // nil for an unlabeled loop
+	Key, Value *Identifier  // Key is nil in the one variable form
+	Iterable   Expression
+	Body       *BlockStatement
+}
+
+func (f *ForInStatement) statementNode()       {}
+func (f *ForInStatement) TokenLiteral() string { return f.Token.Literal }
+func (f *ForInStatement) Pos() token.Position {
+	if f.Label != nil {
+		return f.Label.Pos()
+	}
+	return f.Token.Pos
+}
+func (f *ForInStatement) End() token.Position { return f.Body.End() }
+func (f *ForInStatement) String() string {
+	var b strings.Builder
+	if f.Label != nil {
+		b.WriteString(f.Label.String() + ": ")
+	}
+	b.WriteString(f.TokenLiteral())
+	b.WriteString(" (")
+	if f.Key != nil {
+		b.WriteString(f.Key.String() + ", ")
+	}
+	b.WriteString(f.Value.String())
+	b.WriteString(" in ")
+	b.WriteString(f.Iterable.String())
+	b.WriteString(") ")
+	b.WriteString(f.Body.String())
+	return b.String()
+}
+
+type WhileStatement struct {
+	Token     *token.Token // The 'while' token
+	Label     *Identifier  // nil for an unlabeled loop
+	Condition Expression
+	Body      *BlockStatement
+}
+
+func (w *WhileStatement) statementNode()       {}
+func (w *WhileStatement) TokenLiteral() string { return w.Token.Literal }
+func (w *WhileStatement) Pos() token.Position {
+	if w.Label != nil {
+		return w.Label.Pos()
+	}
+	return w.Token.Pos
+}
+func (w *WhileStatement) End() token.Position { return w.Body.End() }
+func (w *WhileStatement) String() string {
+	var b strings.Builder
+	if w.Label != nil {
+		b.WriteString(w.Label.String() + ": ")
+	}
+	b.WriteString(w.TokenLiteral())
+	b.WriteString(" (")
+	b.WriteString(w.Condition.String())
+	b.WriteString(") ")
+	b.WriteString(w.Body.String())
+	return b.String()
+}
+
+type DoWhileStatement struct {
+	Token     *token.Token // The 'do' token
+	Label     *Identifier  // nil for an unlabeled loop
+	Body      *BlockStatement
+	Condition Expression
+	Rparen    *token.Token // the ')' closing the condition
+}
+
+func (d *DoWhileStatement) statementNode()       {}
+func (d *DoWhileStatement) TokenLiteral() string { return d.Token.Literal }
+func (d *DoWhileStatement) Pos() token.Position {
+	if d.Label != nil {
+		return d.Label.Pos()
+	}
+	return d.Token.Pos
+}
+func (d *DoWhileStatement) End() token.Position { return d.Rparen.End }
+func (d *DoWhileStatement) String() string {
+	var b strings.Builder
+	if d.Label != nil {
+		b.WriteString(d.Label.String() + ": ")
+	}
+	b.WriteString(d.TokenLiteral() + " ")
+	b.WriteString(d.Body.String())
+	b.WriteString(" while (")
+	b.WriteString(d.Condition.String())
+	b.WriteString(")")
+	return b.String()
+}
+
 type ExpressionStatement struct {
 	Token *token.Token // the first token of the expression
 	Expr  Expression
@@ -109,6 +276,8 @@ type ExpressionStatement struct {
 
 func (es *ExpressionStatement) statementNode()       {}
 func (es *ExpressionStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *ExpressionStatement) Pos() token.Position  { return es.Token.Pos }
+func (es *ExpressionStatement) End() token.Position  { return es.Expr.End() }
 func (es *ExpressionStatement) String() (res string) {
 	if es.Expr != nil {
 		res = es.Expr.String()
@@ -117,12 +286,23 @@ func (es *ExpressionStatement) String() (res string) {
 }
 
 type BlockStatement struct {
-	Token *token.Token // the '{' token
-	Stmts []Statement
+	Token  *token.Token // the '{' token
+	Stmts  []Statement
+	Rbrace *token.Token // the '}' token
 }
 
 func (bs *BlockStatement) statementNode()       {}
 func (bs *BlockStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BlockStatement) Pos() token.Position  { return bs.Token.Pos }
+func (bs *BlockStatement) End() token.Position {
+	if bs.Rbrace != nil {
+		return bs.Rbrace.End
+	}
+	if n := len(bs.Stmts); n > 0 {
+		return bs.Stmts[n-1].End()
+	}
+	return bs.Token.End
+}
 func (bs *BlockStatement) String() string {
 	var b strings.Builder
 	stmts := []string{}
@@ -135,6 +315,18 @@ func (bs *BlockStatement) String() string {
 	return b.String()
 }
 
+// BadStatement is a placeholder for a statement containing syntax errors.
+type BadStatement struct {
+	Token *token.Token   // the first token of the statement
+	To    token.Position // end of the skipped tokens
+}
+
+func (bs *BadStatement) statementNode()       {}
+func (bs *BadStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BadStatement) Pos() token.Position  { return bs.Token.Pos }
+func (bs *BadStatement) End() token.Position  { return bs.To }
+func (bs *BadStatement) String() string       { return "<bad statement>;" }
+
 // Expressions
 type Identifier struct {
 	Token *token.Token // the token.IDENT token
@@ -147,6 +339,8 @@ func NewIdentifier(tok *token.Token, v string) *Identifier {
 
 func (i *Identifier) expressionNode()      {}
 func (i *Identifier) TokenLiteral() string { return i.Token.Literal }
+func (i *Identifier) Pos() token.Position  { return i.Token.Pos }
+func (i *Identifier) End() token.Position  { return i.Token.End }
 func (i *Identifier) String() string       { return i.Value }
 
 type StringLiteral struct {
@@ -160,11 +354,14 @@ func NewStringLiteral(tok *token.Token, v string) *StringLiteral {
 
 func (sl *StringLiteral) expressionNode()      {}
 func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *StringLiteral) Pos() token.Position  { return sl.Token.Pos }
+func (sl *StringLiteral) End() token.Position  { return sl.Token.End }
 func (sl *StringLiteral) String() string       { return sl.Token.Literal }
 
 type IntegerLiteral struct {
 	Token *token.Token
 	Value int64
+	Big   *big.Int // set instead of Value when the literal overflows int64
 }
 
 func NewIntegerLiteral(tok *token.Token, v int64) *IntegerLiteral {
@@ -173,8 +370,21 @@ func NewIntegerLiteral(tok *token.Token, v int64) *IntegerLiteral {
 
 func (il *IntegerLiteral) expressionNode()      {}
 func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal }
+func (il *IntegerLiteral) Pos() token.Position  { return il.Token.Pos }
+func (il *IntegerLiteral) End() token.Position  { return il.Token.End }
 func (il *IntegerLiteral) String() string       { return il.Token.Literal }
 
+type FloatLiteral struct {
+	Token *token.Token
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode()      {}
+func (fl *FloatLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FloatLiteral) Pos() token.Position  { return fl.Token.Pos }
+func (fl *FloatLiteral) End() token.Position  { return fl.Token.End }
+func (fl *FloatLiteral) String() string       { return fl.Token.Literal }
+
 type Boolean struct {
 	Token *token.Token
 	Value bool
@@ -186,6 +396,8 @@ func NewBoolean(tok *token.Token, v bool) *Boolean {
 
 func (b *Boolean) expressionNode()      {}
 func (b *Boolean) TokenLiteral() string { return b.Token.Literal }
+func (b *Boolean) Pos() token.Position  { return b.Token.Pos }
+func (b *Boolean) End() token.Position  { return b.Token.End }
 func (b *Boolean) String() string       { return b.Token.Literal }
 
 type PrefixExpression struct {
@@ -196,6 +408,8 @@ type PrefixExpression struct {
 
 func (pe *PrefixExpression) expressionNode()      {}
 func (pe *PrefixExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PrefixExpression) Pos() token.Position  { return pe.Token.Pos }
+func (pe *PrefixExpression) End() token.Position  { return pe.Right.End() }
 func (pe *PrefixExpression) String() string {
 	var b strings.Builder
 	b.WriteString("(")
@@ -213,6 +427,8 @@ type InfixExpression struct {
 
 func (ie *InfixExpression) expressionNode()      {}
 func (ie *InfixExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *InfixExpression) Pos() token.Position  { return ie.Left.Pos() }
+func (ie *InfixExpression) End() token.Position  { return ie.Right.End() }
 func (ie *InfixExpression) String() string {
 	var b strings.Builder
 	b.WriteString("(")
@@ -224,13 +440,21 @@ func (ie *InfixExpression) String() string {
 }
 
 type IfExpression struct {
-	Token                    *token.Token // The 'if' token
-	Condition                Expression
-	Consequence, Alternative *BlockStatement
+	Token       *token.Token // The 'if' token
+	Condition   Expression
+	Consequence *BlockStatement
+	Alternative Node // a *BlockStatement, an *IfExpression for 'else if', or nil
 }
 
 func (ie *IfExpression) expressionNode()      {}
 func (ie *IfExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IfExpression) Pos() token.Position  { return ie.Token.Pos }
+func (ie *IfExpression) End() token.Position {
+	if ie.Alternative != nil {
+		return ie.Alternative.End()
+	}
+	return ie.Consequence.End()
+}
 func (ie *IfExpression) String() string {
 	var b strings.Builder
 	b.WriteString("if ")
@@ -244,36 +468,265 @@ func (ie *IfExpression) String() string {
 	return b.String()
 }
 
+type SwitchExpression struct {
+	Token   *token.Token // The 'switch' token
+	Subject Expression
+	Cases   []*SwitchCase
+	Rbrace  *token.Token // the '}' token
+}
+
+func (se *SwitchExpression) expressionNode()      {}
+func (se *SwitchExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SwitchExpression) Pos() token.Position  { return se.Token.Pos }
+func (se *SwitchExpression) End() token.Position  { return se.Rbrace.End }
+func (se *SwitchExpression) String() string {
+	cases := []string{}
+	for _, c := range se.Cases {
+		cases = append(cases, c.String())
+	}
+	return "switch (" + se.Subject.String() + ") { " + strings.Join(cases, " ") + " }"
+}
+
+// SwitchCase is a 'case' of a switch, or its 'default' if Values is nil.
+type SwitchCase struct {
+	Token  *token.Token // The 'case' or 'default' token
+	Values []Expression
+	Body   *BlockStatement // the statements after the ':', with no braces
+}
+
+func (sc *SwitchCase) TokenLiteral() string { return sc.Token.Literal }
+func (sc *SwitchCase) Pos() token.Position  { return sc.Token.Pos }
+func (sc *SwitchCase) End() token.Position  { return sc.Body.End() }
+func (sc *SwitchCase) String() string {
+	var b strings.Builder
+	b.WriteString(sc.TokenLiteral())
+	if sc.Values != nil {
+		values := []string{}
+		for _, v := range sc.Values {
+			values = append(values, v.String())
+		}
+		b.WriteString(" " + strings.Join(values, ", "))
+	}
+	b.WriteString(":")
+	for _, stmt := range sc.Body.Stmts {
+		b.WriteString(" " + stmt.String())
+	}
+	return b.String()
+}
+
+type MatchExpression struct {
+	Token   *token.Token // The 'match' token
+	Subject Expression
+	Cases   []*MatchCase
+	Rbrace  *token.Token // the '}' token
+}
+
+func (me *MatchExpression) expressionNode()      {}
+func (me *MatchExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MatchExpression) Pos() token.Position  { return me.Token.Pos }
+func (me *MatchExpression) End() token.Position  { return me.Rbrace.End }
+func (me *MatchExpression) String() string {
+	cases := []string{}
+	for _, c := range me.Cases {
+		cases = append(cases, c.String())
+	}
+	return "match (" + me.Subject.String() + ") { " + strings.Join(cases, " ") + " }"
+}
+
+type MatchCase struct {
+	Token   *token.Token // The 'case' token
+	Pattern Pattern
+	Guard   Expression      // the condition after 'if', nil if none
+	Body    *BlockStatement // the statements after the ':', with no braces
+}
+
+func (mc *MatchCase) TokenLiteral() string { return mc.Token.Literal }
+func (mc *MatchCase) Pos() token.Position  { return mc.Token.Pos }
+func (mc *MatchCase) End() token.Position  { return mc.Body.End() }
+func (mc *MatchCase) String() string {
+	var b strings.Builder
+	b.WriteString(mc.TokenLiteral() + " " + mc.Pattern.String())
+	if mc.Guard != nil {
+		b.WriteString(" if " + mc.Guard.String())
+	}
+	b.WriteString(":")
+	for _, stmt := range mc.Body.Stmts {
+		b.WriteString(" " + stmt.String())
+	}
+	return b.String()
+}
+
+// Pattern is the pattern of a match case.
+type Pattern interface {
+	Node
+	patternNode()
+}
+
+// WildcardPattern is '_', which matches anything.
+type WildcardPattern struct {
+	Token *token.Token
+}
+
+func (wp *WildcardPattern) patternNode()         {}
+func (wp *WildcardPattern) TokenLiteral() string { return wp.Token.Literal }
+func (wp *WildcardPattern) Pos() token.Position  { return wp.Token.Pos }
+func (wp *WildcardPattern) End() token.Position  { return wp.Token.End }
+func (wp *WildcardPattern) String() string       { return wp.Token.Literal }
+
+// BindingPattern matches anything and binds it to Name.
+type BindingPattern struct {
+	Name *Identifier
+}
+
+func (bp *BindingPattern) patternNode()         {}
+func (bp *BindingPattern) TokenLiteral() string { return bp.Name.TokenLiteral() }
+func (bp *BindingPattern) Pos() token.Position  { return bp.Name.Pos() }
+func (bp *BindingPattern) End() token.Position  { return bp.Name.End() }
+func (bp *BindingPattern) String() string       { return bp.Name.String() }
+
+// LiteralPattern matches the values equal to a literal, possibly negated.
+type LiteralPattern struct {
+	Value Expression
+}
+
+func (lp *LiteralPattern) patternNode()         {}
+func (lp *LiteralPattern) TokenLiteral() string { return lp.Value.TokenLiteral() }
+func (lp *LiteralPattern) Pos() token.Position  { return lp.Value.Pos() }
+func (lp *LiteralPattern) End() token.Position  { return lp.Value.End() }
+func (lp *LiteralPattern) String() string       { return lp.Value.String() }
+
+// ArrayPattern matches the arrays whose elements match Elements, with any
+// number of further ones if Rest is set.
+type ArrayPattern struct {
+	Token    *token.Token // the '[' token
+	Elements []Pattern
+	Rest     Pattern // a *BindingPattern or *WildcardPattern after '...', or nil
+	Rbracket *token.Token
+}
+
+func (ap *ArrayPattern) patternNode()         {}
+func (ap *ArrayPattern) TokenLiteral() string { return ap.Token.Literal }
+func (ap *ArrayPattern) Pos() token.Position  { return ap.Token.Pos }
+func (ap *ArrayPattern) End() token.Position  { return ap.Rbracket.End }
+func (ap *ArrayPattern) String() string {
+	elements := []string{}
+	for _, e := range ap.Elements {
+		elements = append(elements, e.String())
+	}
+	if ap.Rest != nil {
+		elements = append(elements, "..."+ap.Rest.String())
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+// HashPattern matches the hashes holding each of Keys with a value matching
+// the corresponding one of Values. Other keys are bound to Rest if it is set
+// and ignored otherwise.
+type HashPattern struct {
+	Token  *token.Token // the '{' token
+	Keys   []Expression
+	Values []Pattern
+	Rest   Pattern // a *BindingPattern or *WildcardPattern after '...', or nil
+	Rbrace *token.Token
+}
+
+func (hp *HashPattern) patternNode()         {}
+func (hp *HashPattern) TokenLiteral() string { return hp.Token.Literal }
+func (hp *HashPattern) Pos() token.Position  { return hp.Token.Pos }
+func (hp *HashPattern) End() token.Position  { return hp.Rbrace.End }
+func (hp *HashPattern) String() string {
+	pairs := []string{}
+	for i, k := range hp.Keys {
+		pairs = append(pairs, k.String()+": "+hp.Values[i].String())
+	}
+	if hp.Rest != nil {
+		pairs = append(pairs, "..."+hp.Rest.String())
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// TypePattern matches the values of type Type that match Pattern, as in
+// 'INTEGER(n)'.
+type TypePattern struct {
+	Type    *Identifier
+	Pattern Pattern
+	Rparen  *token.Token
+}
+
+func (tp *TypePattern) patternNode()         {}
+func (tp *TypePattern) TokenLiteral() string { return tp.Type.TokenLiteral() }
+func (tp *TypePattern) Pos() token.Position  { return tp.Type.Pos() }
+func (tp *TypePattern) End() token.Position  { return tp.Rparen.End }
+func (tp *TypePattern) String() string {
+	return tp.Type.String() + "(" + tp.Pattern.String() + ")"
+}
+
 type FunctionLiteral struct {
-	Token  *token.Token // The 'fn' token
-	Params []*Identifier
-	Body   *BlockStatement
+	Token    *token.Token // The 'fn' token
+	Name     *Identifier  // nil for anonymous functions
+	Params   []*Identifier
+	Defaults []Expression // the default value of each of Params, nil if none
+	Rest     *Identifier  // the '...rest' parameter, nil if none
+	Body     *BlockStatement
 }
 
 func (fl *FunctionLiteral) expressionNode()      {}
 func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FunctionLiteral) Pos() token.Position  { return fl.Token.Pos }
+func (fl *FunctionLiteral) End() token.Position  { return fl.Body.End() }
 func (fl *FunctionLiteral) String() string {
 	var b strings.Builder
-	params := []string{}
-	for _, p := range fl.Params {
-		params = append(params, p.String())
-	}
 	b.WriteString(fl.TokenLiteral())
+	if fl.Name != nil {
+		b.WriteString(" " + fl.Name.String())
+	}
 	b.WriteString("(")
-	b.WriteString(strings.Join(params, ", "))
+	b.WriteString(FormatParams(fl.Params, fl.Defaults, fl.Rest))
 	b.WriteString(") ")
 	b.WriteString(fl.Body.String())
 	return b.String()
 }
 
+// FormatParams formats a parameter list as written in a function literal.
+func FormatParams(params []*Identifier, defaults []Expression, rest *Identifier) string {
+	res := []string{}
+	for i, p := range params {
+		if i < len(defaults) && defaults[i] != nil {
+			res = append(res, p.String()+" = "+defaults[i].String())
+		} else {
+			res = append(res, p.String())
+		}
+	}
+	if rest != nil {
+		res = append(res, "..."+rest.String())
+	}
+	return strings.Join(res, ", ")
+}
+
+// SpreadExpression expands an array into the arguments of a call or the
+// elements of an array literal.
+type SpreadExpression struct {
+	Token *token.Token // The '...' token
+	Value Expression
+}
+
+func (se *SpreadExpression) expressionNode()      {}
+func (se *SpreadExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SpreadExpression) Pos() token.Position  { return se.Token.Pos }
+func (se *SpreadExpression) End() token.Position  { return se.Value.End() }
+func (se *SpreadExpression) String() string       { return "..." + se.Value.String() }
+
 type CallExpression struct {
-	Token *token.Token // The '(' token
-	Func  Expression   // Identifier or FunctionLiteral
-	Args  []Expression
+	Token  *token.Token // The '(' token
+	Func   Expression   // Identifier or FunctionLiteral
+	Args   []Expression
+	Rparen *token.Token // The ')' token
 }
 
 func (ce *CallExpression) expressionNode()      {}
 func (ce *CallExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *CallExpression) Pos() token.Position  { return ce.Func.Pos() }
+func (ce *CallExpression) End() token.Position  { return ce.Rparen.End }
 func (ce *CallExpression) String() string {
 	var b strings.Builder
 	args := []string{}
@@ -290,10 +743,13 @@ func (ce *CallExpression) String() string {
 type ArrayLiteral struct {
 	Token    *token.Token // The '[' token
 	Elements []Expression
+	Rbracket *token.Token // The ']' token
 }
 
 func (al *ArrayLiteral) expressionNode()      {}
 func (al *ArrayLiteral) TokenLiteral() string { return al.Token.Literal }
+func (al *ArrayLiteral) Pos() token.Position  { return al.Token.Pos }
+func (al *ArrayLiteral) End() token.Position  { return al.Rbracket.End }
 func (al *ArrayLiteral) String() string {
 	var b strings.Builder
 	elements := []string{}
@@ -306,13 +762,40 @@ func (al *ArrayLiteral) String() string {
 	return b.String()
 }
 
+type HashLiteral struct {
+	Token  *token.Token // The '{' token
+	Pairs  []*HashPair
+	Rbrace *token.Token // The '}' token
+}
+
+type HashPair struct{ Key, Value Expression }
+
+func (hl *HashLiteral) expressionNode()      {}
+func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Literal }
+func (hl *HashLiteral) Pos() token.Position  { return hl.Token.Pos }
+func (hl *HashLiteral) End() token.Position  { return hl.Rbrace.End }
+func (hl *HashLiteral) String() string {
+	var b strings.Builder
+	pairs := []string{}
+	for _, p := range hl.Pairs {
+		pairs = append(pairs, p.Key.String()+": "+p.Value.String())
+	}
+	b.WriteString("{")
+	b.WriteString(strings.Join(pairs, ", "))
+	b.WriteString("}")
+	return b.String()
+}
+
 type IndexExpression struct {
 	Token         *token.Token // The '[' token
 	Left, Indices Expression
+	Rbracket      *token.Token // The ']' token
 }
 
 func (ie *IndexExpression) expressionNode()      {}
 func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IndexExpression) Pos() token.Position  { return ie.Left.Pos() }
+func (ie *IndexExpression) End() token.Position  { return ie.Rbracket.End }
 func (ie *IndexExpression) String() string {
 	var b strings.Builder
 	b.WriteString("(")
@@ -323,6 +806,38 @@ func (ie *IndexExpression) String() string {
 	return b.String()
 }
 
+// SliceExpression is 'left[low:high:step]'; omitted bounds are nil.
+type SliceExpression struct {
+	Token           *token.Token // The '[' token
+	Left            Expression
+	Low, High, Step Expression
+	Rbracket        *token.Token // The ']' token
+}
+
+func (se *SliceExpression) expressionNode()      {}
+func (se *SliceExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SliceExpression) Pos() token.Position  { return se.Left.Pos() }
+func (se *SliceExpression) End() token.Position  { return se.Rbracket.End }
+func (se *SliceExpression) String() string {
+	bound := func(e Expression) string {
+		if e == nil {
+			return ""
+		}
+		return e.String()
+	}
+
+	var b strings.Builder
+	b.WriteString("(")
+	b.WriteString(se.Left.String())
+	b.WriteString("[")
+	b.WriteString(bound(se.Low) + ":" + bound(se.High))
+	if se.Step != nil {
+		b.WriteString(":" + se.Step.String())
+	}
+	b.WriteString("])")
+	return b.String()
+}
+
 type Assignment struct {
 	Token       *token.Token // The '=' token
 	Left, Right Expression
@@ -330,6 +845,8 @@ type Assignment struct {
 
 func (as *Assignment) expressionNode()      {}
 func (as *Assignment) TokenLiteral() string { return as.Token.Literal }
+func (as *Assignment) Pos() token.Position  { return as.Left.Pos() }
+func (as *Assignment) End() token.Position  { return as.Right.End() }
 func (as *Assignment) String() string {
 	var b strings.Builder
 	b.WriteString("(")
@@ -341,22 +858,50 @@ func (as *Assignment) String() string {
 }
 
 type PrefixIncAndDec struct {
-	Token *token.Token // '++' '--' '+='
-	Expr  *Assignment
+	Token    *token.Token // '++' '--'
+	Operator string       // '+' '-'
+	Target   Expression
 }
 
 func (p *PrefixIncAndDec) expressionNode()      {}
 func (p *PrefixIncAndDec) TokenLiteral() string { return p.Token.Literal }
-func (p *PrefixIncAndDec) String() string       { return p.Expr.String() }
+func (p *PrefixIncAndDec) Pos() token.Position  { return p.Token.Pos }
+func (p *PrefixIncAndDec) End() token.Position  { return p.Target.End() }
+func (p *PrefixIncAndDec) String() string       { return "(" + p.Token.Literal + p.Target.String() + ")" }
+
+type PostfixIncAndDec struct {
+	Token    *token.Token // '++' '--'
+	Operator string       // '+' '-'
+	Target   Expression
+}
 
+func (p *PostfixIncAndDec) expressionNode()      {}
+func (p *PostfixIncAndDec) TokenLiteral() string { return p.Token.Literal }
+func (p *PostfixIncAndDec) Pos() token.Position  { return p.Target.Pos() }
+func (p *PostfixIncAndDec) End() token.Position  { return p.Token.End }
+func (p *PostfixIncAndDec) String() string       { return "(" + p.Target.String() + p.Token.Literal + ")" }
+
+// AssignmentConverter is a compound assignment such as 'a += 1', applying
+// Operator to the target and Right.
 type AssignmentConverter struct {
-	Token *token.Token // '++' '--' '+='
-	Expr  *Assignment
+	Token       *token.Token // '+=' '-=' '*=' ...
+	Operator    string       // '+' '-' '*' ...
+	Left, Right Expression
 }
 
 func (ac *AssignmentConverter) expressionNode()      {}
 func (ac *AssignmentConverter) TokenLiteral() string { return ac.Token.Literal }
-func (ac *AssignmentConverter) String() string       { return ac.Expr.String() }
+func (ac *AssignmentConverter) Pos() token.Position  { return ac.Left.Pos() }
+func (ac *AssignmentConverter) End() token.Position  { return ac.Right.End() }
+func (ac *AssignmentConverter) String() string {
+	var b strings.Builder
+	b.WriteString("(")
+	b.WriteString(ac.Left.String())
+	b.WriteString(" " + ac.Token.Literal + " ")
+	b.WriteString(ac.Right.String())
+	b.WriteString(")")
+	return b.String()
+}
 
 type ShortCircuitExpression struct {
 	Token       *token.Token
@@ -366,6 +911,8 @@ type ShortCircuitExpression struct {
 
 func (sc *ShortCircuitExpression) expressionNode()      {}
 func (sc *ShortCircuitExpression) TokenLiteral() string { return sc.Token.Literal }
+func (sc *ShortCircuitExpression) Pos() token.Position  { return sc.Left.Pos() }
+func (sc *ShortCircuitExpression) End() token.Position  { return sc.Right.End() }
 func (sc *ShortCircuitExpression) String() string {
 	var b strings.Builder
 	b.WriteString("(")
@@ -383,6 +930,8 @@ type ExpressionList struct {
 
 func (el *ExpressionList) expressionNode()      {}
 func (el *ExpressionList) TokenLiteral() string { return el.Token.Literal }
+func (el *ExpressionList) Pos() token.Position  { return el.Exprs[0].Pos() }
+func (el *ExpressionList) End() token.Position  { return el.Exprs[len(el.Exprs)-1].End() }
 func (el *ExpressionList) String() string {
 	var b strings.Builder
 	exprs := []string{}
@@ -0,0 +1,76 @@
+package ast
+
+import (
+	"fmt"
+	"go-interpreter/token"
+	"reflect"
+	"strings"
+)
+
+var (
+	tokenType    = reflect.TypeOf((*token.Token)(nil))
+	positionType = reflect.TypeOf(token.Position{})
+)
+
+// Dump returns an indented tree of node and its children, one node per line
+// with its source span. Tokens are left out since the spans cover them.
+func Dump(node Node) string {
+	var b strings.Builder
+	dumpValue(&b, reflect.ValueOf(node), 0)
+	return b.String()
+}
+
+func dumpValue(b *strings.Builder, v reflect.Value, depth int) {
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		b.WriteString("nil\n")
+		return
+	}
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.Elem().Kind() != reflect.Struct {
+			dumpValue(b, v.Elem(), depth)
+			return
+		}
+
+		fmt.Fprintf(b, "%s", v.Type())
+		if n, ok := v.Interface().(Node); ok {
+			if pos := n.Pos(); pos.IsValid() {
+				end := n.End()
+				fmt.Fprintf(b, " (%d:%d-%d:%d)", pos.Line, pos.Column, end.Line, end.Column)
+			}
+		}
+		b.WriteString("\n")
+
+		s := v.Elem()
+		for i := 0; i < s.NumField(); i++ {
+			f := s.Type().Field(i)
+			if !f.IsExported() || f.Type == tokenType || f.Type == positionType {
+				continue
+			}
+			fmt.Fprintf(b, "%s%s: ", strings.Repeat("  ", depth+1), f.Name)
+			dumpValue(b, s.Field(i), depth+1)
+		}
+
+	case reflect.Slice:
+		if v.Len() == 0 {
+			b.WriteString("[]\n")
+			return
+		}
+		b.WriteString("[\n")
+		for i := 0; i < v.Len(); i++ {
+			fmt.Fprintf(b, "%s%d: ", strings.Repeat("  ", depth+1), i)
+			dumpValue(b, v.Index(i), depth+1)
+		}
+		fmt.Fprintf(b, "%s]\n", strings.Repeat("  ", depth))
+
+	case reflect.String:
+		fmt.Fprintf(b, "%q\n", v.String())
+
+	default:
+		fmt.Fprintf(b, "%v\n", v.Interface())
+	}
+}
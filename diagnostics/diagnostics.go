@@ -0,0 +1,146 @@
+package diagnostics
+
+import (
+	"errors"
+	"fmt"
+	"go-interpreter/token"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type Diagnostic struct {
+	Pos, End token.Position
+	Msg      string
+	Notes    []string
+	Hint     string
+}
+
+func New(pos, end token.Position, msg string) *Diagnostic {
+	return &Diagnostic{Pos: pos, End: end, Msg: msg}
+}
+
+// FromError converts err into a Diagnostic, keeping the span of a
+// *token.Error when there is one.
+func FromError(err error) *Diagnostic {
+	var e *token.Error
+	if errors.As(err, &e) {
+		return New(e.Pos, e.End, e.Msg)
+	}
+	return New(token.Position{}, token.Position{}, err.Error())
+}
+
+func (d *Diagnostic) WithNote(note string) *Diagnostic {
+	d.Notes = append(d.Notes, note)
+	return d
+}
+
+func (d *Diagnostic) WithHint(hint string) *Diagnostic {
+	d.Hint = hint
+	return d
+}
+
+// Printer renders diagnostics compiler-style, quoting the offending source
+// line for every file it has been given the text of.
+type Printer struct {
+	w       io.Writer
+	sources map[string][]string
+}
+
+func NewPrinter(w io.Writer) *Printer {
+	return &Printer{w: w, sources: map[string][]string{}}
+}
+
+// AddSource registers (or replaces) the text of filename.
+func (p *Printer) AddSource(filename, src string) {
+	p.sources[filename] = strings.Split(src, "\n")
+}
+
+// AppendSource extends the text of filename, e.g. with a new REPL input.
+func (p *Printer) AppendSource(filename, src string) {
+	lines := p.sources[filename]
+	if n := len(lines); n > 0 && lines[n-1] == "" {
+		lines = lines[:n-1]
+	}
+	p.sources[filename] = append(lines, strings.Split(src, "\n")...)
+}
+
+func (p *Printer) PrintErrors(errs []error) {
+	for _, err := range errs {
+		p.PrintError(err)
+	}
+}
+
+func (p *Printer) PrintError(err error) {
+	p.Print(FromError(err))
+}
+
+func (p *Printer) Print(d *Diagnostic) {
+	if d.Pos.IsValid() || d.Pos.Filename != "" {
+		fmt.Fprintf(p.w, "%s: error: %s\n", d.Pos, d.Msg)
+	} else {
+		fmt.Fprintf(p.w, "error: %s\n", d.Msg)
+	}
+
+	gutter := ""
+	if line, ok := p.line(d.Pos); ok {
+		num := strconv.Itoa(d.Pos.Line)
+		gutter = strings.Repeat(" ", len(num)+1)
+		fmt.Fprintf(p.w, " %s | %s\n", num, line)
+		fmt.Fprintf(p.w, "%s | %s\n", gutter, underline(line, d.Pos, d.End))
+	}
+
+	for _, note := range d.Notes {
+		fmt.Fprintf(p.w, "%s = note: %s\n", gutter, note)
+	}
+	if d.Hint != "" {
+		fmt.Fprintf(p.w, "%s = hint: %s\n", gutter, d.Hint)
+	}
+}
+
+func (p *Printer) line(pos token.Position) (string, bool) {
+	lines, ok := p.sources[pos.Filename]
+	if !ok || !pos.IsValid() || pos.Line > len(lines) {
+		return "", false
+	}
+	return strings.TrimRight(lines[pos.Line-1], "\r"), true
+}
+
+// underline returns the caret line marking [pos, end) within line, whose
+// columns count runes. Spans running past the end of the line are cut off
+// there.
+func underline(s string, pos, end token.Position) string {
+	line := []rune(s)
+	start := pos.Column - 1
+	if start > len(line) {
+		start = len(line)
+	}
+
+	width := 1
+	if end.Line == pos.Line && end.Column > pos.Column {
+		width = end.Column - pos.Column
+	} else if end.Line > pos.Line {
+		width = len(line) - start
+	}
+	if width < 1 {
+		width = 1
+	}
+
+	var b strings.Builder
+	for i := 0; i < start; i++ {
+		if line[i] == '\t' {
+			b.WriteRune('\t')
+		} else {
+			b.WriteByte(' ')
+		}
+	}
+	b.WriteString(strings.Repeat("^", width))
+	return b.String()
+}
+
+// Fprint renders errs against the text src of filename.
+func Fprint(w io.Writer, filename, src string, errs ...error) {
+	p := NewPrinter(w)
+	p.AddSource(filename, src)
+	p.PrintErrors(errs)
+}
@@ -0,0 +1,82 @@
+package diagnostics
+
+import (
+	"errors"
+	"go-interpreter/token"
+	"strings"
+	"testing"
+)
+
+func TestPrint(t *testing.T) {
+	src := "let a = 1;\n\tlet b = a + c;\nlet π = é + é;\n"
+
+	tests := []struct {
+		err      error
+		notes    []string
+		hint     string
+		expected string
+	}{
+		{
+			token.NewError(
+				token.Position{Filename: "a.yl", Offset: 24, Line: 2, Column: 14},
+				token.Position{Filename: "a.yl", Offset: 25, Line: 2, Column: 15},
+				"name 'c' is not defined",
+			),
+			nil, "",
+			"a.yl:2:14: error: name 'c' is not defined\n" +
+				" 2 | \tlet b = a + c;\n" +
+				"   | \t            ^\n",
+		},
+		{
+			token.NewError(
+				token.Position{Filename: "a.yl", Offset: 0, Line: 1, Column: 1},
+				token.Position{Filename: "a.yl", Offset: 9, Line: 1, Column: 10},
+				"bad statement",
+			),
+			[]string{"first note"}, "try this",
+			"a.yl:1:1: error: bad statement\n" +
+				" 1 | let a = 1;\n" +
+				"   | ^^^^^^^^^\n" +
+				"   = note: first note\n" +
+				"   = hint: try this\n",
+		},
+		{
+			token.NewError(
+				token.Position{Filename: "a.yl", Offset: 30, Line: 3, Column: 13},
+				token.Position{Filename: "a.yl", Offset: 31, Line: 3, Column: 14},
+				"name 'é' is not defined",
+			),
+			nil, "",
+			"a.yl:3:13: error: name 'é' is not defined\n" +
+				" 3 | let π = é + é;\n" +
+				"   |             ^\n",
+		},
+		{
+			errors.New("something failed"),
+			nil, "",
+			"error: something failed\n",
+		},
+		{
+			token.NewError(token.Position{Filename: "b.yl", Line: 3, Column: 1}, token.Position{}, "unknown file"),
+			nil, "",
+			"b.yl:3:1: error: unknown file\n",
+		},
+	}
+
+	for _, tt := range tests {
+		var b strings.Builder
+		p := NewPrinter(&b)
+		p.AddSource("a.yl", src)
+
+		d := FromError(tt.err)
+		for _, n := range tt.notes {
+			d.WithNote(n)
+		}
+		d.WithHint(tt.hint)
+		p.Print(d)
+
+		if b.String() != tt.expected {
+			t.Errorf("wrong output.\nexpected=\n%s\ngot=\n%s", tt.expected, b.String())
+		}
+	}
+}
@@ -4,8 +4,14 @@ import (
 	"errors"
 	"fmt"
 	"go-interpreter/object"
+	"io"
+	"math"
+	"math/big"
 	"os"
 	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
 type (
@@ -13,6 +19,9 @@ type (
 	doubleOperandFn func(object.Object, object.Object) (object.Object, error)
 )
 
+// Stdout is where the 'print' builtin writes to.
+var Stdout io.Writer = os.Stdout
+
 var builtins = map[string]*object.Builtin{
 	"len": {
 		Fn: func(args ...object.Object) (object.Object, error) {
@@ -22,21 +31,107 @@ var builtins = map[string]*object.Builtin{
 
 			switch arg := args[0].(type) {
 			case *object.String:
-				return object.NewInteger(int64(len(arg.Value))), nil
+				return object.NewInteger(int64(utf8.RuneCountInString(arg.Value))), nil
 			case *object.Array:
 				return object.NewInteger(int64(len(arg.Elements))), nil
+			case *object.Hash:
+				return object.NewInteger(int64(len(arg.Pairs))), nil
+			case *object.Range:
+				return object.IntegerFromBig(arg.Len()), nil
 			default:
 				return nil, fmt.Errorf("argument to 'len' not supported, got '%s'", args[0].Type())
 			}
 		},
 	},
-	"exit": {
+	"int": {
 		Fn: func(args ...object.Object) (object.Object, error) {
-			if err := checkArgsLen(len(args), 0); err != nil {
+			if err := checkArgsLen(len(args), 1); err != nil {
 				return nil, err
 			}
 
-			os.Exit(0)
+			switch arg := args[0].(type) {
+			case *object.Integer, *object.BigInteger:
+				return arg, nil
+			case *object.Boolean:
+				return object.NewInteger(objectToInteger(arg)), nil
+			case *object.Float:
+				if math.IsNaN(arg.Value) || math.IsInf(arg.Value, 0) {
+					return nil, fmt.Errorf("cannot convert float %s to integer", arg.Inspect())
+				}
+				v, _ := big.NewFloat(arg.Value).Int(nil)
+				return object.IntegerFromBig(v), nil
+			case *object.String:
+				v, ok := new(big.Int).SetString(strings.TrimSpace(arg.Value), 10)
+				if !ok {
+					return nil, fmt.Errorf("invalid literal for int(): %s", arg.Inspect())
+				}
+				return object.IntegerFromBig(v), nil
+			default:
+				return nil, fmt.Errorf("argument to 'int' not supported, got '%s'", args[0].Type())
+			}
+		},
+	},
+	"float": {
+		Fn: func(args ...object.Object) (object.Object, error) {
+			if err := checkArgsLen(len(args), 1); err != nil {
+				return nil, err
+			}
+
+			switch arg := args[0].(type) {
+			case *object.Float:
+				return arg, nil
+			case *object.Integer, *object.BigInteger, *object.Boolean:
+				f, _ := objectToFloat(arg)
+				return object.NewFloat(f), nil
+			case *object.String:
+				v, err := strconv.ParseFloat(strings.TrimSpace(arg.Value), 64)
+				if err != nil {
+					return nil, fmt.Errorf("could not convert string to float: %s", arg.Inspect())
+				}
+				return object.NewFloat(v), nil
+			default:
+				return nil, fmt.Errorf("argument to 'float' not supported, got '%s'", args[0].Type())
+			}
+		},
+	},
+	"exit": {
+		Fn: func(args ...object.Object) (object.Object, error) {
+			if len(args) > 1 {
+				return nil, fmt.Errorf("wrong number of arguments: got=%d, want=0 or 1", len(args))
+			}
+
+			code := 0
+			if len(args) == 1 {
+				switch arg := args[0].(type) {
+				case *object.Integer:
+					if arg.Value < 0 || arg.Value > 255 {
+						return nil, fmt.Errorf("exit code must be between 0 and 255, got %d", arg.Value)
+					}
+					code = int(arg.Value)
+				case *object.BigInteger:
+					return nil, fmt.Errorf("exit code must be between 0 and 255, got %s", arg.Inspect())
+				default:
+					return nil, fmt.Errorf("argument to 'exit' must be 'INTEGER', got '%s'", arg.Type())
+				}
+			}
+
+			os.Exit(code)
+
+			return object.NULL, nil
+		},
+	},
+	"print": {
+		Fn: func(args ...object.Object) (object.Object, error) {
+			strs := []string{}
+			for _, arg := range args {
+				if s, ok := arg.(*object.String); ok {
+					strs = append(strs, s.Value)
+				} else {
+					strs = append(strs, arg.Inspect())
+				}
+			}
+
+			fmt.Fprintln(Stdout, strings.Join(strs, " "))
 
 			return object.NULL, nil
 		},
@@ -122,6 +217,111 @@ var builtins = map[string]*object.Builtin{
 			return arr, nil
 		},
 	},
+	"keys": {
+		Fn: func(args ...object.Object) (object.Object, error) {
+			if err := checkArgsLen(len(args), 1); err != nil {
+				return nil, err
+			}
+
+			hash, err := checkIsHash("keys", args[0])
+			if err != nil {
+				return nil, err
+			}
+
+			res := make([]object.Object, 0, len(hash.Keys))
+			for _, k := range hash.Keys {
+				res = append(res, hash.Pairs[k].Key)
+			}
+			return object.NewArray(res), nil
+		},
+	},
+	"values": {
+		Fn: func(args ...object.Object) (object.Object, error) {
+			if err := checkArgsLen(len(args), 1); err != nil {
+				return nil, err
+			}
+
+			hash, err := checkIsHash("values", args[0])
+			if err != nil {
+				return nil, err
+			}
+
+			res := make([]object.Object, 0, len(hash.Keys))
+			for _, k := range hash.Keys {
+				res = append(res, hash.Pairs[k].Value)
+			}
+			return object.NewArray(res), nil
+		},
+	},
+	"has": {
+		Fn: func(args ...object.Object) (object.Object, error) {
+			if err := checkArgsLen(len(args), 2); err != nil {
+				return nil, err
+			}
+
+			hash, err := checkIsHash("has", args[0])
+			if err != nil {
+				return nil, err
+			}
+
+			key, err := checkIsHashable(args[1])
+			if err != nil {
+				return nil, err
+			}
+
+			_, ok := hash.Get(key)
+			return object.NewBoolean(ok), nil
+		},
+	},
+	"delete": {
+		Fn: func(args ...object.Object) (object.Object, error) {
+			if err := checkArgsLen(len(args), 2); err != nil {
+				return nil, err
+			}
+
+			hash, err := checkIsHash("delete", args[0])
+			if err != nil {
+				return nil, err
+			}
+
+			key, err := checkIsHashable(args[1])
+			if err != nil {
+				return nil, err
+			}
+
+			if val, ok := hash.Delete(key); ok {
+				return val, nil
+			}
+			return object.NULL, nil
+		},
+	},
+	"range": {
+		Fn: func(args ...object.Object) (object.Object, error) {
+			if len(args) == 0 || len(args) > 3 {
+				return nil, fmt.Errorf("wrong number of arguments: got=%d, want=1 to 3", len(args))
+			}
+
+			bounds := []int64{0, 0, 1}
+			for i, arg := range args {
+				if arg.Type() != object.INTEGER_OBJ {
+					return nil, fmt.Errorf("argument to 'range' must be 'INTEGER', got '%s'", arg.Type())
+				}
+				n, ok := arg.(*object.Integer)
+				if !ok {
+					return nil, errors.New("range() arguments must fit in 64 bits")
+				}
+				bounds[i] = n.Value
+			}
+			if len(args) == 1 {
+				bounds[0], bounds[1] = 0, bounds[0]
+			}
+
+			if bounds[2] == 0 {
+				return nil, errors.New("range() step cannot be zero")
+			}
+			return object.NewRange(bounds[0], bounds[1], bounds[2]), nil
+		},
+	},
 	"sum": {
 		Fn: func(args ...object.Object) (object.Object, error) {
 			if len(args) == 0 || len(args) > 2 {
@@ -152,6 +352,16 @@ var builtins = map[string]*object.Builtin{
 	},
 }
 
+// BuiltinNames returns the names of all builtin functions, sorted.
+func BuiltinNames() []string {
+	res := make([]string, 0, len(builtins))
+	for name := range builtins {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
+
 func reduce(fn doubleOperandFn, arr []object.Object, initializer object.Object) (res object.Object, err error) {
 	res = initializer
 
@@ -192,3 +402,19 @@ func checkIsArray(fn string, obj object.Object) (*object.Array, error) {
 	}
 	return arr, nil
 }
+
+func checkIsHash(fn string, obj object.Object) (*object.Hash, error) {
+	hash, ok := obj.(*object.Hash)
+	if !ok {
+		return nil, fmt.Errorf("argument to '%s' must be 'HASH', got '%s'", fn, obj.Type())
+	}
+	return hash, nil
+}
+
+func checkIsHashable(obj object.Object) (object.Hashable, error) {
+	key, ok := obj.(object.Hashable)
+	if !ok {
+		return nil, fmt.Errorf("unhashable type: '%s'", obj.Type())
+	}
+	return key, nil
+}
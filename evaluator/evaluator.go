@@ -5,9 +5,19 @@ import (
 	"fmt"
 	"go-interpreter/ast"
 	"go-interpreter/object"
+	"go-interpreter/token"
+	"strings"
 )
 
 func Eval(node ast.Node, env *object.Environment) (object.Object, error) {
+	res, err := eval(node, env)
+	if err != nil {
+		return nil, withPosition(node, err)
+	}
+	return res, nil
+}
+
+func eval(node ast.Node, env *object.Environment) (object.Object, error) {
 	switch node := node.(type) {
 	case *ast.Program:
 		return evalProgram(node, env)
@@ -27,6 +37,22 @@ func Eval(node ast.Node, env *object.Environment) (object.Object, error) {
 	case *ast.ForLoopStatement:
 		return evalForLoopStatement(node, env)
 
+	case *ast.ForInStatement:
+		return evalForInStatement(node, env)
+
+	case *ast.WhileStatement:
+		return evalLoop(node.Label, node.Condition, nil, node.Body, true, env)
+
+	case *ast.DoWhileStatement:
+		return evalLoop(node.Label, node.Condition, nil, node.Body, false, env)
+
+	case *ast.BranchStatement:
+		return nil, evalBranchStatement(node)
+
+	case *ast.FunctionDeclaration:
+		// Bound when hoisted by the enclosing block.
+		return nil, nil
+
 	case *ast.ExpressionList:
 		return evalExpressionList(node, env)
 
@@ -36,6 +62,9 @@ func Eval(node ast.Node, env *object.Environment) (object.Object, error) {
 	case *ast.IntegerLiteral:
 		return evalIntegerLiteral(node)
 
+	case *ast.FloatLiteral:
+		return object.NewFloat(node.Value), nil
+
 	case *ast.Boolean:
 		return evalBoolean(node)
 
@@ -54,6 +83,9 @@ func Eval(node ast.Node, env *object.Environment) (object.Object, error) {
 	case *ast.PrefixIncAndDec:
 		return evalPrefixIncAndDec(node, env)
 
+	case *ast.PostfixIncAndDec:
+		return evalPostfixIncAndDec(node, env)
+
 	case *ast.AssignmentConverter:
 		return evalAssignmentConverter(node, env)
 
@@ -63,6 +95,12 @@ func Eval(node ast.Node, env *object.Environment) (object.Object, error) {
 	case *ast.IfExpression:
 		return evalIfExpression(node, env)
 
+	case *ast.SwitchExpression:
+		return evalSwitchExpression(node, env)
+
+	case *ast.MatchExpression:
+		return evalMatchExpression(node, env)
+
 	case *ast.FunctionLiteral:
 		return evalFunctionLiteral(node, env)
 
@@ -72,42 +110,78 @@ func Eval(node ast.Node, env *object.Environment) (object.Object, error) {
 	case *ast.ArrayLiteral:
 		return evalArrayLiteral(node, env)
 
+	case *ast.HashLiteral:
+		return evalHashLiteral(node, env)
+
 	case *ast.IndexExpression:
 		return evalIndexExpression(node, env, false)
 
+	case *ast.SliceExpression:
+		return evalSliceExpression(node, env, false)
+
 	default:
 		return nil, errors.New("invalid syntax")
 	}
 }
 
 func evalProgram(p *ast.Program, env *object.Environment) (res object.Object, err error) {
+	if err = hoistFunctions(p.Stmts, env); err != nil {
+		return
+	}
+
 	for _, statement := range p.Stmts {
 		if res, err = Eval(statement, env); err != nil {
-			return
+			return catchReturn(nil, err)
 		}
+	}
+
+	return
+}
+
+// evalBlockStatement runs bs in a scope of its own, so the names it declares
+// shadow outer ones and do not outlive it.
+func evalBlockStatement(bs *ast.BlockStatement, env *object.Environment) (object.Object, error) {
+	return evalStatements(bs.Stmts, object.NewEnclosedEnvironment(env))
+}
 
-		if returnValue, ok := res.(*object.ReturnValue); ok {
-			res = returnValue.Value
-			return
+func evalStatements(stmts []ast.Statement, env *object.Environment) (res object.Object, err error) {
+	if err = hoistFunctions(stmts, env); err != nil {
+		return
+	}
+
+	for _, statement := range stmts {
+		if res, err = Eval(statement, env); err != nil {
+			return nil, err
 		}
 	}
 
 	return
 }
 
-// TODO scope
-func evalBlockStatement(bs *ast.BlockStatement, env *object.Environment) (res object.Object, err error) {
-	for _, statement := range bs.Stmts {
-		if res, err = Eval(statement, env); err != nil {
-			return
+// hoistFunctions binds the functions declared among stmts before any of them
+// runs, so they can be called ahead of their declaration and from each other.
+func hoistFunctions(stmts []ast.Statement, env *object.Environment) error {
+	for _, statement := range stmts {
+		fd, ok := statement.(*ast.FunctionDeclaration)
+		if !ok {
+			continue
 		}
 
-		if res != nil && res.Type() == object.RETURN_VALUE_OBJ {
-			return
+		name := fd.Func.Name
+		if env.IsExist(name.Value) {
+			return newError(name, "identifier '%s' has already been declared", name.Value)
 		}
+		env.Set(name.Value, object.NewFunction(fd.Func, env))
 	}
+	return nil
+}
 
-	return
+func evalBranchStatement(bs *ast.BranchStatement) error {
+	signal := &branchSignal{tok: bs.Token.Type}
+	if bs.Label != nil {
+		signal.label = bs.Label.Value
+	}
+	return signal
 }
 
 func evalExpressionStatement(es *ast.ExpressionStatement, env *object.Environment) (object.Object, error) {
@@ -127,7 +201,9 @@ func evalLetStatement(ls *ast.LetStatement, env *object.Environment) (object.Obj
 			return nil, err
 		}
 		for _, id := range idents {
-			id.Set(object.NULL)
+			if err := id.Set(object.NULL); err != nil {
+				return nil, err
+			}
 		}
 
 	default:
@@ -139,17 +215,16 @@ func evalLetStatement(ls *ast.LetStatement, env *object.Environment) (object.Obj
 
 func evalReturnStatement(rs *ast.ReturnStatement, env *object.Environment) (object.Object, error) {
 	if rs.ReturnValue == nil {
-		return object.NewReturnValue(object.NULL), nil
+		return nil, &returnSignal{object.NULL}
 	}
 
 	val, err := Eval(rs.ReturnValue, env)
 	if err != nil {
 		return nil, err
 	}
-	return object.NewReturnValue(val), nil
+	return nil, &returnSignal{val}
 }
 
-// TODO break continue
 func evalForLoopStatement(fs *ast.ForLoopStatement, env *object.Environment) (object.Object, error) {
 	env = object.NewEnclosedEnvironment(env)
 	if fs.Init != nil {
@@ -157,10 +232,17 @@ func evalForLoopStatement(fs *ast.ForLoopStatement, env *object.Environment) (ob
 			return nil, err
 		}
 	}
+	return evalLoop(fs.Label, fs.Condition, fs.Update, fs.Body, true, env)
+}
 
-	for {
-		if fs.Condition != nil {
-			cond, err := Eval(fs.Condition, env)
+// evalLoop runs body until condition, checked before each iteration if
+// checkFirst is set and after it otherwise, is falsy. A nil condition never
+// ends the loop. update runs after each iteration, including one ended by a
+// 'continue'.
+func evalLoop(label *ast.Identifier, condition, update ast.Expression, body *ast.BlockStatement, checkFirst bool, env *object.Environment) (object.Object, error) {
+	for first := true; ; first = false {
+		if condition != nil && (checkFirst || !first) {
+			cond, err := Eval(condition, env)
 			if err != nil {
 				return nil, err
 			}
@@ -168,11 +250,18 @@ func evalForLoopStatement(fs *ast.ForLoopStatement, env *object.Environment) (ob
 				break
 			}
 		}
-		if _, err := Eval(fs.Body, env); err != nil {
-			return nil, err
+		if _, err := Eval(body, env); err != nil {
+			bs, ok := err.(*branchSignal)
+			if !ok || !targets(label, bs.label) {
+				return nil, err
+			}
+			if bs.tok == token.BREAK {
+				break
+			}
 		}
-		if fs.Update != nil {
-			if _, err := Eval(fs.Update, env); err != nil {
+
+		if update != nil {
+			if _, err := Eval(update, env); err != nil {
 				return nil, err
 			}
 		}
@@ -180,6 +269,50 @@ func evalForLoopStatement(fs *ast.ForLoopStatement, env *object.Environment) (ob
 	return nil, nil
 }
 
+// evalForInStatement runs the body of fs once per element of its iterable,
+// each time in a new scope binding the loop variables, so closures created by
+// the body see the values of their own iteration.
+func evalForInStatement(fs *ast.ForInStatement, env *object.Environment) (object.Object, error) {
+	iterable, err := Eval(fs.Iterable, env)
+	if err != nil {
+		return nil, err
+	}
+
+	newIter := newIterator
+	if fs.Key == nil {
+		newIter = newElementIterator
+	}
+	next, err := newIter(iterable)
+	if err != nil {
+		return nil, withPosition(fs.Iterable, err)
+	}
+
+	for key, value, ok := next(); ok; key, value, ok = next() {
+		loopEnv := object.NewEnclosedEnvironment(env)
+		if fs.Key != nil {
+			loopEnv.Set(fs.Key.Value, key)
+		}
+		loopEnv.Set(fs.Value.Value, value)
+
+		if _, err := Eval(fs.Body, loopEnv); err != nil {
+			bs, ok := err.(*branchSignal)
+			if !ok || !targets(fs.Label, bs.label) {
+				return nil, err
+			}
+			if bs.tok == token.BREAK {
+				break
+			}
+		}
+	}
+	return nil, nil
+}
+
+// targets reports whether a 'break' or 'continue' with label applies to the
+// loop labeled loopLabel.
+func targets(loopLabel *ast.Identifier, label string) bool {
+	return label == "" || loopLabel != nil && loopLabel.Value == label
+}
+
 func evalIdentifier(ident *ast.Identifier, env *object.Environment) (object.Object, error) {
 	name := ident.Value
 	if val, _ := env.Get(name); val != nil {
@@ -198,6 +331,9 @@ func evalStringLiteral(s *ast.StringLiteral) (object.Object, error) {
 }
 
 func evalIntegerLiteral(il *ast.IntegerLiteral) (object.Object, error) {
+	if il.Big != nil {
+		return object.IntegerFromBig(il.Big), nil
+	}
 	return object.NewInteger(il.Value), nil
 }
 
@@ -267,16 +403,57 @@ func evalShortCircuitExpression(sc *ast.ShortCircuitExpression, env *object.Envi
 }
 
 func evalPrefixIncAndDec(p *ast.PrefixIncAndDec, env *object.Environment) (object.Object, error) {
-	return evalAssignment(p.Expr, env, false)
+	_, res, err := evalCompoundAssignment(p.Target, p.Operator, env, one)
+	return res, err
+}
+
+// evalPostfixIncAndDec is like evalPrefixIncAndDec but returns the old value.
+func evalPostfixIncAndDec(p *ast.PostfixIncAndDec, env *object.Environment) (object.Object, error) {
+	old, _, err := evalCompoundAssignment(p.Target, p.Operator, env, one)
+	return old, err
+}
+
+func one() (object.Object, error) {
+	return object.NewInteger(1), nil
 }
 
 func evalAssignmentConverter(ac *ast.AssignmentConverter, env *object.Environment) (object.Object, error) {
-	if _, err := evalAssignment(ac.Expr, env, false); err != nil {
+	_, _, err := evalCompoundAssignment(ac.Left, ac.Operator, env, func() (object.Object, error) {
+		return Eval(ac.Right, env)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return nil, nil
 }
 
+// evalCompoundAssignment sets target to 'target operator right()' and
+// returns its old and new value. The target, including any index expression
+// within it, is evaluated only once, and its old value is read before right
+// runs.
+func evalCompoundAssignment(target ast.Expression, operator string, env *object.Environment, right func() (object.Object, error)) (old, res object.Object, err error) {
+	as, err := checkAssignable(target, env, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	old = as[0].Get()
+
+	r, err := right()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res, err = evalBinaryOperator(operator, old, r)
+	if err != nil {
+		return nil, nil, withPosition(target, err)
+	}
+
+	if err = as[0].Set(res); err != nil {
+		return nil, nil, withPosition(target, err)
+	}
+	return old, res, nil
+}
+
 func evalAssignment(ae *ast.Assignment, env *object.Environment, isDeclaration bool) (object.Object, error) {
 	as, err := checkAssignable(ae.Left, env, isDeclaration)
 	if err != nil {
@@ -303,7 +480,7 @@ func checkAssignable(e ast.Expression, env *object.Environment, isDeclaration bo
 	case *ast.Identifier:
 		ident, err := newIdentifier(e.Value, env, isDeclaration)
 		if err != nil {
-			return nil, err
+			return nil, withPosition(e, err)
 		}
 		res = append(res, ident)
 		return res, nil
@@ -327,9 +504,19 @@ func checkAssignable(e ast.Expression, env *object.Environment, isDeclaration bo
 			res = append(res, ie.(object.Assignable))
 			return res, nil
 		}
+
+	case *ast.SliceExpression:
+		if !isDeclaration {
+			se, err := evalSliceExpression(e, env, true)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, se.(object.Assignable))
+			return res, nil
+		}
 	}
 
-	return nil, errors.New("invalid syntax")
+	return nil, newError(e, "invalid syntax")
 }
 
 func newIdentifier(name string, env *object.Environment, isDeclaration bool) (*object.Identifier, error) {
@@ -356,13 +543,17 @@ func assign(as []object.Assignable, r object.Object) (object.Object, error) {
 			return nil, errors.New("the lengths of the left and right sides of '=' are not equal")
 		}
 		for i, o := range expList.Elements {
-			as[i].Set(o)
+			if err := as[i].Set(o); err != nil {
+				return nil, err
+			}
 		}
 	} else {
 		if len(as) != 1 {
 			return nil, errors.New("the lengths of the left and right sides of '=' are not equal")
 		}
-		as[0].Set(r)
+		if err := as[0].Set(r); err != nil {
+			return nil, err
+		}
 	}
 	return r, nil
 }
@@ -390,8 +581,60 @@ func evalIfExpression(ie *ast.IfExpression, env *object.Environment) (object.Obj
 	return object.NULL, nil
 }
 
+// evalSwitchExpression evaluates the body of the first case with a value
+// equal to the subject, as with '==', or else of the default case. It is
+// null if no case runs.
+func evalSwitchExpression(se *ast.SwitchExpression, env *object.Environment) (object.Object, error) {
+	subject, err := Eval(se.Subject, env)
+	if err != nil {
+		return nil, err
+	}
+
+	var def *ast.SwitchCase
+	for _, c := range se.Cases {
+		if c.Values == nil {
+			def = c
+			continue
+		}
+		matched, err := caseMatches(c, subject, env)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			return Eval(c.Body, env)
+		}
+	}
+
+	if def != nil {
+		return Eval(def.Body, env)
+	}
+	return object.NULL, nil
+}
+
+// caseMatches compares subject with the values of c in order, evaluating
+// them only up to the first equal one.
+func caseMatches(c *ast.SwitchCase, subject object.Object, env *object.Environment) (bool, error) {
+	for _, v := range c.Values {
+		vals, err := evalExpressions([]ast.Expression{v}, env)
+		if err != nil {
+			return false, err
+		}
+
+		for _, val := range vals {
+			res, err := eq(subject, val)
+			if err != nil {
+				return false, withPosition(v, err)
+			}
+			if res == object.TRUE {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
 func evalFunctionLiteral(fl *ast.FunctionLiteral, env *object.Environment) (object.Object, error) {
-	return object.NewFunction(fl.Params, fl.Body, env), nil
+	return object.NewFunction(fl, env), nil
 }
 
 func evalCallExpression(ce *ast.CallExpression, env *object.Environment) (object.Object, error) {
@@ -408,35 +651,100 @@ func evalCallExpression(ce *ast.CallExpression, env *object.Environment) (object
 	return applyFunction(fn, args)
 }
 
+// evalExpressions evaluates exps in order, expanding spread collections in
+// place.
 func evalExpressions(exps []ast.Expression, env *object.Environment) ([]object.Object, error) {
 	res := []object.Object{}
 	for _, e := range exps {
+		spread, isSpread := e.(*ast.SpreadExpression)
+		if isSpread {
+			e = spread.Value
+		}
+
 		evaluated, err := Eval(e, env)
 		if err != nil {
 			return nil, err
 		}
-		res = append(res, evaluated)
+
+		if !isSpread {
+			res = append(res, evaluated)
+			continue
+		}
+		next, err := newElementIterator(evaluated)
+		if err != nil {
+			return nil, newError(spread, "cannot spread '%s'", evaluated.Type())
+		}
+		for _, v, ok := next(); ok; _, v, ok = next() {
+			res = append(res, v)
+		}
 	}
 	return res, nil
 }
 
-func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
+// extendFunctionEnv binds the arguments of a call to the parameters of fn.
+// Missing arguments take their default values, evaluated in order so they can
+// refer to earlier parameters, and the rest parameter collects extra ones.
+func extendFunctionEnv(fn *object.Function, args []object.Object) (*object.Environment, error) {
+	if err := checkArity(fn, len(args)); err != nil {
+		return nil, err
+	}
+
 	env := object.NewEnclosedEnvironment(fn.Env)
 	for i, param := range fn.Parameters {
-		env.Set(param.Value, args[i])
+		if i < len(args) {
+			env.Set(param.Value, args[i])
+			continue
+		}
+
+		val, err := Eval(fn.Defaults[i], env)
+		if err != nil {
+			return nil, err
+		}
+		env.Set(param.Value, val)
+	}
+
+	if fn.Rest != nil {
+		rest := []object.Object{}
+		if len(args) > len(fn.Parameters) {
+			rest = append(rest, args[len(fn.Parameters):]...)
+		}
+		env.Set(fn.Rest.Value, object.NewArray(rest))
+	}
+	return env, nil
+}
+
+func checkArity(fn *object.Function, got int) error {
+	required, total := 0, len(fn.Parameters)
+	for i := range fn.Parameters {
+		if fn.Defaults[i] == nil {
+			required = i + 1
+		}
 	}
-	return env
+
+	switch {
+	case fn.Rest != nil:
+		if got < required {
+			return fmt.Errorf("wrong number of arguments: got=%d, want>=%d", got, required)
+		}
+	case required == total:
+		if got != required {
+			return fmt.Errorf("wrong number of arguments: got=%d, want=%d", got, required)
+		}
+	case got < required || got > total:
+		return fmt.Errorf("wrong number of arguments: got=%d, want=%d to %d", got, required, total)
+	}
+	return nil
 }
 
 func applyFunction(obj object.Object, args []object.Object) (object.Object, error) {
 	switch fn := obj.(type) {
 	case *object.Function:
-		extendEnv := extendFunctionEnv(fn, args)
-		res, err := Eval(fn.Body, extendEnv)
+		// The body shares the scope of the parameters, which it cannot redeclare.
+		extendEnv, err := extendFunctionEnv(fn, args)
 		if err != nil {
 			return nil, err
 		}
-		return unwrapReturnValue(res), nil
+		return catchReturn(evalStatements(fn.Body.Stmts, extendEnv))
 
 	case *object.Builtin:
 		return fn.Fn(args...)
@@ -455,6 +763,31 @@ func evalArrayLiteral(al *ast.ArrayLiteral, env *object.Environment) (object.Obj
 	return object.NewArray(elements), nil
 }
 
+func evalHashLiteral(hl *ast.HashLiteral, env *object.Environment) (object.Object, error) {
+	hash := object.NewHash()
+
+	for _, p := range hl.Pairs {
+		k, err := Eval(p.Key, env)
+		if err != nil {
+			return nil, err
+		}
+
+		key, ok := k.(object.Hashable)
+		if !ok {
+			return nil, newError(p.Key, "unhashable type: '%s'", k.Type())
+		}
+
+		v, err := Eval(p.Value, env)
+		if err != nil {
+			return nil, err
+		}
+
+		hash.Set(key, v)
+	}
+
+	return hash, nil
+}
+
 func evalIndexExpression(ie *ast.IndexExpression, env *object.Environment, isAssignment bool) (object.Object, error) {
 	l, err := Eval(ie.Left, env)
 	if err != nil {
@@ -473,25 +806,193 @@ func evalIndexExpression(ie *ast.IndexExpression, env *object.Environment, isAss
 		}
 
 		return evalArrayIndexExpression(l, idx, isAssignment)
+	case object.STRING_OBJ:
+		if isAssignment {
+			return nil, errors.New("strings do not support item assignment")
+		}
+		if idx.Type() != object.INTEGER_OBJ {
+			return nil, fmt.Errorf("string indices must be integers, not '%s'", idx.Type())
+		}
+
+		return evalStringIndexExpression(l, idx)
+	case object.HASH_OBJ:
+		return evalHashIndexExpression(l, idx, isAssignment)
 	default:
 		return nil, fmt.Errorf("index operator not supported: '%s'", l.Type())
 	}
 }
 
+// normalizeIndex resolves a possibly negative index, counting from the end,
+// against length and reports whether it is in range.
+func normalizeIndex(index object.Object, length int) (int, bool) {
+	i, ok := index.(*object.Integer)
+	if !ok {
+		return 0, false
+	}
+	idx := i.Value
+	if idx < 0 {
+		idx += int64(length)
+	}
+	return int(idx), idx >= 0 && idx < int64(length)
+}
+
 func evalArrayIndexExpression(array, index object.Object, isAssignment bool) (object.Object, error) {
-	arr, idx := array.(*object.Array).Elements, index.(*object.Integer).Value
-	if idx < 0 || idx >= int64(len(arr)) {
+	arr := array.(*object.Array)
+	idx, ok := normalizeIndex(index, len(arr.Elements))
+	if !ok {
 		return nil, fmt.Errorf("array index out of range")
 	}
 	if isAssignment {
-		return object.NewArrayIndex(arr, idx), nil
+		return object.NewArrayIndex(arr, int64(idx)), nil
+	}
+	return arr.Elements[idx], nil
+}
+
+// evalStringIndexExpression indexes a string by runes.
+func evalStringIndexExpression(str, index object.Object) (object.Object, error) {
+	runes := []rune(str.(*object.String).Value)
+	idx, ok := normalizeIndex(index, len(runes))
+	if !ok {
+		return nil, fmt.Errorf("string index out of range")
+	}
+	return object.NewString(string(runes[idx])), nil
+}
+
+func evalSliceExpression(se *ast.SliceExpression, env *object.Environment, isAssignment bool) (object.Object, error) {
+	l, err := Eval(se.Left, env)
+	if err != nil {
+		return nil, err
+	}
+
+	var bounds [3]object.Object
+	for i, e := range []ast.Expression{se.Low, se.High, se.Step} {
+		if e == nil {
+			continue
+		}
+		if bounds[i], err = Eval(e, env); err != nil {
+			return nil, err
+		}
+		if bounds[i].Type() != object.INTEGER_OBJ {
+			return nil, withPosition(e, fmt.Errorf("slice indices must be integers, not '%s'", bounds[i].Type()))
+		}
 	}
-	return arr[idx], nil
+
+	switch l := l.(type) {
+	case *object.Array:
+		start, stop, step, err := sliceIndices(bounds, len(l.Elements))
+		if err != nil {
+			return nil, err
+		}
+		slice := object.NewArraySlice(l, start, stop, step)
+		if isAssignment {
+			return slice, nil
+		}
+		return slice.Get(), nil
+
+	case *object.String:
+		if isAssignment {
+			return nil, errors.New("strings do not support slice assignment")
+		}
+		runes := []rune(l.Value)
+		start, stop, step, err := sliceIndices(bounds, len(runes))
+		if err != nil {
+			return nil, err
+		}
+		var b strings.Builder
+		for i := start; step > 0 && i < stop || step < 0 && i > stop; i += step {
+			b.WriteRune(runes[i])
+		}
+		return object.NewString(b.String()), nil
+
+	default:
+		return nil, fmt.Errorf("slice operator not supported: '%s'", l.Type())
+	}
+}
+
+// sliceIndices resolves the low, high and step bounds of a slice of a
+// sequence of length elements the way Python does: negative bounds count from
+// the end, bounds out of range are clamped and omitted ones (nil) default to
+// the whole sequence in the direction of step.
+func sliceIndices(bounds [3]object.Object, length int) (start, stop, step int, err error) {
+	// Any bound beyond ±(length+1) behaves the same, which also keeps the
+	// arithmetic below from overflowing.
+	clamp := func(obj object.Object) int {
+		switch obj := obj.(type) {
+		case *object.Integer:
+			if obj.Value > int64(length) {
+				return length + 1
+			} else if obj.Value < -int64(length) {
+				return -length - 1
+			}
+			return int(obj.Value)
+		case *object.BigInteger:
+			return obj.Value.Sign() * (length + 1)
+		}
+		return 0
+	}
+
+	step = 1
+	if bounds[2] != nil {
+		if step = clamp(bounds[2]); step == 0 {
+			return 0, 0, 0, errors.New("slice step cannot be zero")
+		}
+	}
+
+	resolve := func(obj object.Object, def int) int {
+		if obj == nil {
+			return def
+		}
+		i := clamp(obj)
+		if i < 0 {
+			i += length
+		}
+		if step > 0 {
+			if i < 0 {
+				return 0
+			} else if i > length {
+				return length
+			}
+		} else {
+			if i < 0 {
+				return -1
+			} else if i >= length {
+				return length - 1
+			}
+		}
+		return i
+	}
+
+	if step > 0 {
+		return resolve(bounds[0], 0), resolve(bounds[1], length), step, nil
+	}
+	return resolve(bounds[0], length-1), resolve(bounds[1], -1), step, nil
+}
+
+func evalHashIndexExpression(hash, index object.Object, isAssignment bool) (object.Object, error) {
+	h := hash.(*object.Hash)
+	key, ok := index.(object.Hashable)
+	if !ok {
+		return nil, fmt.Errorf("unhashable type: '%s'", index.Type())
+	}
+	if isAssignment {
+		return object.NewHashIndex(h, key), nil
+	}
+	if val, ok := h.Get(key); ok {
+		return val, nil
+	}
+	return object.NULL, nil
+}
+
+func newError(node ast.Node, format string, a ...any) error {
+	return token.NewError(node.Pos(), node.End(), fmt.Sprintf(format, a...))
 }
 
-func unwrapReturnValue(obj object.Object) object.Object {
-	if returnValue, ok := obj.(*object.ReturnValue); ok {
-		return returnValue.Value
+// withPosition attaches the span of node to err unless err already carries one
+// or is a signal.
+func withPosition(node ast.Node, err error) error {
+	var e *token.Error
+	if _, ok := err.(signal); ok || errors.As(err, &e) {
+		return err
 	}
-	return obj
+	return token.NewError(node.Pos(), node.End(), err.Error())
 }
@@ -1,9 +1,14 @@
 package evaluator
 
 import (
+	"go-interpreter/ast"
 	"go-interpreter/lexer"
 	"go-interpreter/object"
 	"go-interpreter/parser"
+	"go-interpreter/token"
+	"math/big"
+	"os"
+	"strings"
 	"testing"
 )
 
@@ -29,6 +34,90 @@ func TestEvalIntegerExpression(t *testing.T) {
 		{"3 * 3 * 3 + 10", 37},
 		{"3 * (3 * 3) + 10", 37},
 		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+		{"0xFF", 255},
+		{"0o755", 493},
+		{"0b1010 | 0b0101", 15},
+		{"1_000_000", 1000000},
+		{"0xFF & ~0x0F", 240},
+		{"0xFFFF_FFFF_FFFF_FFFF", bigInt("18446744073709551615")},
+	}
+
+	autoTest(t, tests)
+}
+
+func TestEvalFloatExpression(t *testing.T) {
+	tests := []test{
+		{"1.5", 1.5},
+		{"-2.5", -2.5},
+		{"1e3", 1000.0},
+		{"3 / 2.0", 1.5},
+		{"1.5 * 2", 3.0},
+		{"true + 0.5", 1.5},
+		{"10 - 0.25", 9.75},
+		{"7.5 % 2", 1.5},
+		{"-7.5 % 2", 0.5},
+		{"1 < 1.5", true},
+		{"2.0 == 2", true},
+		{"2.5 >= 3", false},
+		{"!0.0", true},
+		{"1 / 0.0", "1:1: float division by zero"},
+		{"~1.5", "1:1: bad operand type for unary ~: 'FLOAT'"},
+	}
+
+	autoTest(t, tests)
+}
+
+func TestFloatInspect(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"2.0", "2.0"},
+		{"0.1 + 0.2", "0.30000000000000004"},
+		{"1e100", "1e+100"},
+		{"-0.5", "-0.5"},
+		{"float(3)", "3.0"},
+	}
+
+	for _, tt := range tests {
+		res, err := testEval(tt.input)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if res.Inspect() != tt.expected {
+			t.Errorf("wrong Inspect for %q. got=%q, want=%q", tt.input, res.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestBigIntegers(t *testing.T) {
+	fact := "let fact = fn(n) { if (n <= 1) { return 1; } n * fact(n - 1) }; "
+
+	tests := []test{
+		{"9223372036854775807 + 1", bigInt("9223372036854775808")},
+		{"-9223372036854775807 - 2", bigInt("-9223372036854775809")},
+		{"-(-9223372036854775807 - 1)", bigInt("9223372036854775808")},
+		{"(-9223372036854775807 - 1) / -1", bigInt("9223372036854775808")},
+		{"1 << 70", bigInt("1180591620717411303424")},
+		{"~(1 << 64)", bigInt("-18446744073709551617")},
+		{"123456789012345678901234567890", bigInt("123456789012345678901234567890")},
+		{fact + "fact(25)", bigInt("15511210043330985984000000")},
+		{fact + "fact(25) / fact(23)", 600},
+		{fact + "fact(21) - fact(21) + 1", 1},
+		{"(1 << 70) >> 69", 2},
+		{"(1 << 64) % 10", 6},
+		{"-(1 << 64) % 10", 4},
+		{"(1 << 64) & 255", 0},
+		{"(1 << 64) > 9223372036854775807", true},
+		{"(1 << 64) == 18446744073709551616", true},
+		{"(1 << 64) == 1.8446744073709552e19", true},
+		{`{18446744073709551616: "big"}[1 << 64]`, "big"},
+		{"int(1e20)", bigInt("100000000000000000000")},
+		{`int("-99999999999999999999")`, bigInt("-99999999999999999999")},
+		{"float(1 << 64)", 1.8446744073709552e19},
+		{"1 << (1 << 64)", "1:1: shift count too large"},
+		{`"a" * (1 << 64)`, "1:1: repeat count too large"},
+		{"[1, 2][1 << 64]", "1:1: array index out of range"},
 	}
 
 	autoTest(t, tests)
@@ -119,6 +208,10 @@ func TestIfElseExpressions(t *testing.T) {
 		{"if (1 > 2) { 10 }", nil},
 		{"if (1 > 2) { 10 } else { 20 }", 20},
 		{"if (1 < 2) { 10 } else { 20 }", 10},
+		{"if (false) { 10 } else if (true) { 20 } else { 30 }", 20},
+		{"if (false) { 10 } else if (false) { 20 } else { 30 }", 30},
+		{"if (false) { 10 } else if (false) { 20 }", nil},
+		{"let x = 3; if (x == 1) { 1 } else if (x == 2) { 2 } else if (x == 3) { 3 }", 3},
 	}
 
 	autoTest(t, tests)
@@ -172,7 +265,7 @@ func TestLetStatements(t *testing.T) {
 		{"let a = 5 * 5; a;", 25},
 		{"let a = 5; let b = a; b;", 5},
 		{"let a = 5; let b = a; let c = a + b + 5; c;", 15},
-		{"let a = 1; let b, a = 2, 2;", "identifier 'a' has already been declared"},
+		{"let a = 1; let b, a = 2, 2;", "1:19: identifier 'a' has already been declared"},
 		{"let a, b, c, d = 1, 2, 3, 4; [a, b, c, d]", []any{1, 2, 3, 4}},
 	}
 
@@ -191,6 +284,275 @@ func TestForLoopStatements(t *testing.T) {
 	autoTest(t, tests)
 }
 
+func TestBlockScope(t *testing.T) {
+	tests := []test{
+		{"let x = 1; if (true) { let x = 2 } x", 1},
+		{"if (true) { let y = 1 } y", "1:25: name 'y' is not defined"},
+		{"let f = fn() { if (true) { let x = 1; x } }; f(); f()", 1},
+		{"for (let i = 0; i < 3; i++) { let x = i }", nil},
+		{"let x = 1; { let x = 2; x = 3 } x", 1},
+		{"let x = 1; { x = 2 } x", 2},
+		{"{ let z = 5; z }", 5},
+		{"let x = 1; { let x = 2; { let x = 3 } x }", 2},
+		{"let fs = []; for (let i = 0; i < 3; i++) { let j = i; fs = fs + [fn() { j }] } fs[1]()", 1},
+		{"let f = fn(a) { let a = 2 }; f(1)", "1:21: identifier 'a' has already been declared"},
+	}
+
+	autoTest(t, tests)
+}
+
+func TestBranchStatements(t *testing.T) {
+	tests := []test{
+		{"let s = 0; for (let i = 0; i < 10; i++) { if (i == 3) { break } s += i } s", 3},
+		{"let s = 0; for (let i = 0; i < 5; i++) { if (i % 2 == 0) { continue } s += i } s", 4},
+		{"let i = 0; for (;;) { i++; if (i > 4) { break } } i", 5},
+		{`let n = 0
+		outer: for (let i = 0; i < 3; i++) {
+			for (let j = 0; j < 3; j++) {
+				if (j == 1) { continue outer }
+				if (i == 2) { break outer }
+				n += 1
+			}
+		}
+		n`, 2},
+		{`let n = 0
+		for (let i = 0; i < 3; i++) {
+			for (let j = 0; j < 3; j++) {
+				if (j == 2) { break }
+				n += 1
+			}
+		}
+		n`, 6},
+		{"let f = fn() { for (;;) { break } return 1 }; f()", 1},
+	}
+
+	autoTest(t, tests)
+}
+
+func TestControlFlow(t *testing.T) {
+	tests := []test{
+		// return from inside loops
+		{"fn f() { for (let i = 0; i < 10; i++) { if (i == 3) { return i } } return -1 } f()", 3},
+		{"fn f() { for (;;) { return 1 } } f()", 1},
+		{"let n = 0; fn f() { for (;;) { n++; return; } } f(); f(); n", 2},
+		{`fn f() {
+			for (let i = 0; i < 3; i++) {
+				for (let j = 0; j < 3; j++) {
+					if (i * j == 2) { return [i, j] }
+				}
+			}
+		}
+		f()`, []any{1, 2}},
+		{"fn f() { outer: for (;;) { for (;;) { return 7; break outer } } return 0 } f()", 7},
+		{"fn f() { { { return 1 } } 2 } f()", 1},
+		{"return 5; 6", 5},
+		{"for (;;) { return 1 } 2", 1},
+
+		// return inside an if used as a value
+		{"fn f() { let x = if (true) { return 5 } else { 1 }; x + 1 } f()", 5},
+		{"fn f() { 1 + if (true) { return 2 } else { 3 } } f()", 2},
+		{"fn f() { print(if (true) { return 3 }) } f()", 3},
+		{"fn f() { [1, if (true) { return 4 }] } f()", 4},
+
+		// return only leaves the innermost function
+		{`fn f() {
+			let n = 0
+			for (let i = 0; i < 5; i++) {
+				let g = fn() { return i }
+				n += g()
+			}
+			n
+		}
+		f()`, 10},
+		{"fn f() { let g = fn() { for (;;) { return 1 } }; g() + 1 } f()", 2},
+		{"let fs = []; for (let i = 0; i < 3; i++) { let j = i; fs = fs + [fn() { for (;;) { return j * 2 } }] } fs[2]()", 4},
+
+		// break and continue inside ifs inside functions inside loops
+		{`let out = []
+		for (let i = 0; i < 6; i++) {
+			if (i % 2 == 0) { continue }
+			if (i > 4) { break }
+			out = out + [fn() { if (true) { return i } }()]
+		}
+		out`, []any{1, 3}},
+		{"fn f() { let s = 0; for (let i = 0; i < 10; i++) { if (i == 5) { break } s += i } return s } f()", 10},
+		{"let x = 0; for (;;) { x += if (x < 3) { 1 } else { break } } x", 3},
+	}
+
+	autoTest(t, tests)
+}
+
+func TestForInStatements(t *testing.T) {
+	tests := []test{
+		{"let s = 0; for (x in [1, 2, 3]) { s += x } s", 6},
+		{"let s = []; for (i, x in [5, 6]) { s = s + [i, x] } s", []any{0, 5, 1, 6}},
+		{`let s = []; for (c in "hé!") { s = s + [c] } s`, []any{"h", "é", "!"}},
+		{`let s = []; for (i, c in "hé") { s = s + [i] } s`, []any{0, 1}},
+		{`let s = []; for (k in {"a": 1, "b": 2}) { s = s + [k] } s`, []any{"a", "b"}},
+		{`let s = []; for (k, v in {"a": 1, "b": 2}) { s = s + [k, v] } s`, []any{"a", 1, "b", 2}},
+		{`let h = {"a": 1, "b": 2, "c": 3}; let s = []; for (k in h) { delete(h, "b"); s = s + [k] } s`, []any{"a", "c"}},
+		{"let s = 0; for (let x in range(5)) { s += x } s", 10},
+		{"let s = 0; for (x in []) { s += 1 } s", 0},
+		{"let fs = []; for (x in range(3)) { fs = fs + [fn() { x }] } [fs[0](), fs[2]()]", []any{0, 2}},
+		{"let x = 7; for (x in range(3)) {} x", 7},
+		{"let s = 0; for (x in range(10)) { if (x % 2 == 0) { continue } if (x > 6) { break } s += x } s", 9},
+		{`let n = 0
+		outer: for (x in range(3)) {
+			for (y in range(3)) {
+				if (y == 1) { continue outer }
+				if (x == 2) { break outer }
+				n += 1
+			}
+		}
+		n`, 2},
+		{"fn f() { for (x in range(10)) { if (x == 4) { return x } } } f()", 4},
+		{"for (x in 5) {}", "1:11: 'INTEGER' is not iterable"},
+	}
+
+	autoTest(t, tests)
+}
+
+func TestSwitchExpressions(t *testing.T) {
+	tests := []test{
+		{"switch (1) { case 1: 10 case 2: 20 }", 10},
+		{"switch (2) { case 1: 10 case 2: 20 }", 20},
+		{"switch (3) { case 1: 10 case 2: 20 }", nil},
+		{"switch (3) { case 1: 10 default: 0 }", 0},
+		{"switch (1) { default: 0 case 1: 10 }", 10},
+		{`switch ("b") { case "a", "b": 1 case "c": 2 }`, 1},
+		{"switch (1.0) { case 1: true }", true},
+		{"switch (3) { case ...[1, 2, 3]: true }", true},
+		{"switch (1) { case 1: }", nil},
+		{"switch (1) { }", nil},
+		{"let x = switch (2) { case 1: 10 case 2: let y = 5; y * 4 }; x", 20},
+		{"switch (1) { case 1: let y = 1 } y", "1:34: name 'y' is not defined"},
+		{"let n = 0; let f = fn() { n += 1; n }; switch (1) { case 1, f(): 0 } n", 0},
+		{"fn f(x) { switch (x) { case 1: return 10 } 20 } [f(1), f(2)]", []any{10, 20}},
+		{`let n = 0
+		for (i in range(5)) {
+			switch (i) {
+			case 1: continue
+			case 3: break
+			}
+			n += 1
+		}
+		n`, 2},
+		{`switch (1) { case "a": 1 }`, "1:19: '==' not supported between 'INTEGER' and 'STRING'"},
+		{"switch (x) { }", "1:9: name 'x' is not defined"},
+	}
+
+	autoTest(t, tests)
+}
+
+func TestMatchExpressions(t *testing.T) {
+	tests := []test{
+		{"match (1) { case 1: 10 case 2: 20 }", 10},
+		{"match (2.0) { case 1: 10 case 2: 20 }", 20},
+		{"match (-3) { case -3: true }", true},
+		{`match ("a") { case 1: 1 case "a": 2 }`, 2},
+		{"match (5) { case 1: 1 case _: 0 }", 0},
+		{"match (5) { case n: n * 2 }", 10},
+		{"match (5) { case n if n > 9: 1 case n if n > 4: 2 case _: 3 }", 2},
+		{"match ([1, 2, 3]) { case [a, b]: 0 case [a, b, c]: a + b + c }", 6},
+		{"match ([1, 2, 3]) { case [first, ...rest]: [first, rest] }", []any{1, []any{2, 3}}},
+		{"match ([1]) { case [a, ...rest]: rest }", []any{}},
+		{"match ([]) { case [a, ..._]: 1 case []: 2 }", 2},
+		{"match ([1, [2, 3]]) { case [a, [b, c]]: a + b + c }", 6},
+		{`match ("ab") { case [a, b]: 1 case _: 2 }`, 2},
+		{`match ({"name": "bo", "age": 3}) { case {name: n, "age": a}: [n, a] }`, []any{"bo", 3}},
+		{`match ({"name": "bo"}) { case {name, age}: 1 case {name}: name }`, "bo"},
+		{`match ({1: "x", true: "y"}) { case {1: a, true: b}: a + b }`, "xy"},
+		{`match ({"kind": "k", "a": 1}) { case {kind: "k", ...rest}: [keys(rest), values(rest)] }`, []any{[]any{"a"}, []any{1}}},
+		{`match ({"kind": "j"}) { case {kind: "k"}: 1 case {kind: k}: k }`, "j"},
+		{"match ([1]) { case {a}: 1 case _: 2 }", 2},
+		{"match (1) { case INTEGER(n): n case _: 0 }", 1},
+		{`match ("s") { case INTEGER(n): n case STRING(_): "str" }`, "str"},
+		{"match ([1]) { case ARRAY([x]): x }", 1},
+		{"match (range(2)) { case RANGE(_): true }", true},
+		{"match (len) { case BUILTIN(f): f([1, 2]) }", 2},
+		{"let n = 1; match (2) { case n: n } n", 1},
+		{"match (1) { case n: let m = n } m", "1:33: name 'm' is not defined"},
+		{"fn f(x) { match (x) { case [a]: return a case _: 0 } } [f([7]), f(1)]", []any{7, 0}},
+		{"match (5) { case 1: 1 }", "1:8: no case matches 5"},
+		{"match ([1]) { case [2]: 1 }", "1:8: no case matches [1]"},
+		{"match (1) { case Foo(n): 1 }", "1:18: unknown type 'Foo'"},
+		{"match (1) { case n if m: 1 }", "1:23: name 'm' is not defined"},
+	}
+
+	autoTest(t, tests)
+}
+
+func TestMatchUnhashableKey(t *testing.T) {
+	tok := &token.Token{
+		Type:    token.IDENT,
+		Literal: "k",
+		Pos:     token.Position{Line: 1, Column: 2},
+		End:     token.Position{Line: 1, Column: 3},
+	}
+	key := ast.NewIdentifier(tok, "k")
+	pat := &ast.HashPattern{Keys: []ast.Expression{key}, Values: []ast.Pattern{&ast.WildcardPattern{}}}
+
+	env := object.NewEnvironment()
+	env.Set("k", object.NewArray(nil))
+
+	_, err := matchPattern(pat, object.NewHash(), env)
+	if err == nil || err.Error() != "1:2: unhashable type: 'ARRAY'" {
+		t.Errorf("wrong error. got=%v", err)
+	}
+}
+
+func TestWhileStatements(t *testing.T) {
+	tests := []test{
+		{"let i = 0; while (i < 5) { i += 1 } i", 5},
+		{"let i = 0; while (false) { i += 1 } i", 0},
+		{"let i = 0; do { i += 1 } while (false) i", 1},
+		{"let i = 0; do { i += 1 } while (i < 5); i", 5},
+		{"let i = 0; let s = 0; while (i < 5) { i += 1; if (i == 2) { continue } s += i } s", 13},
+		{"let i = 0; do { i += 1; if (i < 3) { continue } break } while (true) i", 3},
+		{"let n = 0; outer: while (true) { do { n += 1; break outer } while (true) } n", 1},
+		{"let n = 0; l: do { n += 1; continue l } while (n < 3) n", 3},
+		{"fn f() { let i = 0; while (true) { i += 1; if (i == 4) { return i } } } f()", 4},
+		{"let i = 0; while (i < 1) { let x = 1; i += x } x", "1:48: name 'x' is not defined"},
+		{"let fs = []; let i = 0; while (i < 2) { let j = i; fs = fs + [fn() { j }]; i += 1 } [fs[0](), fs[1]()]", []any{0, 1}},
+		{"while (y) {}", "1:8: name 'y' is not defined"},
+		{"do {} while (y)", "1:14: name 'y' is not defined"},
+	}
+
+	autoTest(t, tests)
+}
+
+func TestRangeBuiltin(t *testing.T) {
+	tests := []test{
+		{"[...range(4)]", []any{0, 1, 2, 3}},
+		{"[...range(2, 5)]", []any{2, 3, 4}},
+		{"[...range(10, 0, -3)]", []any{10, 7, 4, 1}},
+		{"[...range(0, 10, -1)]", []any{}},
+		{"[...range(9223372036854775805, 9223372036854775807, 5)]", []any{int64(9223372036854775805)}},
+		{"len(range(0, 10, 3))", 4},
+		{"len(range(5, 0))", 0},
+		{"if (range(0)) { 1 } else { 2 }", 2},
+		{"if (range(5, 0)) { 1 } else { 2 }", 2},
+		{"if (range(3)) { 1 } else { 2 }", 1},
+		{"!range(0)", true},
+		{"len(range(-9223372036854775807 - 1, 9223372036854775807))", bigInt("18446744073709551615")},
+		{`[...{"a": 1}, ..."bc"]`, []any{"a", "b", "c"}},
+		{"range(1, 2, 0)", "1:1: range() step cannot be zero"},
+		{`range("a")`, "1:1: argument to 'range' must be 'INTEGER', got 'STRING'"},
+		{"range(1 << 64)", "1:1: range() arguments must fit in 64 bits"},
+		{"range()", "1:1: wrong number of arguments: got=0, want=1 to 3"},
+	}
+
+	autoTest(t, tests)
+
+	res, err := testEval("range(0, 10, 2)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Inspect() != "range(0, 10, 2)" {
+		t.Errorf("wrong Inspect. got=%q", res.Inspect())
+	}
+}
+
 func TestFunctionObject(t *testing.T) {
 	input := "fn(x) { x + 2; };"
 
@@ -233,6 +595,67 @@ func TestFunctionApplication(t *testing.T) {
 	autoTest(t, tests)
 }
 
+func TestFunctionArguments(t *testing.T) {
+	tests := []test{
+		{"let f = fn(a, b) { a }; f(1)", "1:25: wrong number of arguments: got=1, want=2"},
+		{"let f = fn(a) { a }; f(1, 2)", "1:22: wrong number of arguments: got=2, want=1"},
+		{"let f = fn(a, b = 2) { a + b }; f(1)", 3},
+		{"let f = fn(a, b = 2) { a + b }; f(1, 5)", 6},
+		{"let f = fn(a, b = 2) { a + b }; f()", "1:33: wrong number of arguments: got=0, want=1 to 2"},
+		{"let f = fn(a, b = a * 10) { b }; f(3)", 30},
+		{"let n = 0; let f = fn(a = n++) { a }; f(); f(); n", 2},
+		{"let f = fn(a, ...rest) { rest }; f(1, 2, 3)", []any{2, 3}},
+		{"let f = fn(a, ...rest) { rest }; f(1)", []any{}},
+		{"let f = fn(a, ...rest) { rest }; f()", "1:34: wrong number of arguments: got=0, want>=1"},
+		{"let f = fn(a, b, c) { a + b + c }; f(...[1, 2, 3])", 6},
+		{"let f = fn(a, b, c) { a + b + c }; let xs = [2, 3]; f(1, ...xs)", 6},
+		{"let f = fn(...xs) { len(xs) }; f(...[1, 2], 3, ...[])", 3},
+		{"len(...[[1, 2]])", 2},
+		{"let a = [2, 3]; [1, ...a, 4]", []any{1, 2, 3, 4}},
+		{"let f = fn(a) { a }; f(...5)", "1:24: cannot spread 'INTEGER'"},
+	}
+
+	autoTest(t, tests)
+}
+
+func TestFunctionDeclarations(t *testing.T) {
+	tests := []test{
+		{"fn add(a, b) { a + b } add(1, 2)", 3},
+		{"let x = double(4); fn double(n) { n * 2 } x", 8},
+		{`fn isEven(n) { if (n == 0) { return true } isOdd(n - 1) }
+		fn isOdd(n) { if (n == 0) { return false } isEven(n - 1) }
+		[isEven(10), isOdd(7), isEven(3)]`, []any{true, true, false}},
+		{"fn f() { return g(); fn g() { 1 } } f()", 1},
+		{"if (true) { fn h() { 1 } } h()", "1:28: name 'h' is not defined"},
+		{"let f = 1; fn f() {}", "1:5: identifier 'f' has already been declared"},
+		{"fn f() {} fn f() {}", "1:14: identifier 'f' has already been declared"},
+		{"fn f(n) { fn n() {} }; f(1)", "1:14: identifier 'n' has already been declared"},
+	}
+
+	autoTest(t, tests)
+}
+
+func TestFunctionInspect(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn(a) { a }", "fn(a) {\n{ a; }\n}"},
+		{"fn(a, b = 1 + 1, ...c) { a }", "fn(a, b = (1 + 1), ...c) {\n{ a; }\n}"},
+		{"fn id(a) { a } id", "fn id(a) {\n{ a; }\n}"},
+	}
+
+	for _, tt := range tests {
+		res, err := testEval(tt.input)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if res.Inspect() != tt.expected {
+			t.Errorf("wrong Inspect for %q. got=%q, want=%q", tt.input, res.Inspect(), tt.expected)
+		}
+	}
+}
+
 func TestEnvironment(t *testing.T) {
 	tests := []test{
 		{"let a = 1; a = 2; a", 2},
@@ -271,17 +694,43 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`len([])`, 0},
 		{`len("four")`, 4},
 		{`len("hello world")`, 11},
+		{`len("héllo")`, 5},
 		{`len([1, 2, 3, 4, 5, 6, 7])`, 7},
 		{`sum([])`, 0},
 		{`sum([], 2)`, 2},
 		{`sum([1, 2, 3, 4, 5, 6, 7])`, 28},
 		{`sum([1, 2, 3, 4, 5, 6, 7], 8)`, 36},
 		{`sum(["1", "23", "456"])`, "123456"},
+		{`sum([1, 2.5])`, 3.5},
+		{`int(3.9)`, 3},
+		{`int(-3.9)`, -3},
+		{`int(true)`, 1},
+		{`int(" 42 ")`, 42},
+		{`int("4.2")`, `1:1: invalid literal for int(): "4.2"`},
+		{`float(2)`, 2.0},
+		{`float("2.5")`, 2.5},
+		{`float("x")`, `1:1: could not convert string to float: "x"`},
+		{`{1.0: "one"}[1]`, "one"},
 	}
 
 	autoTest(t, tests)
 }
 
+func TestPrintBuiltin(t *testing.T) {
+	var b strings.Builder
+	Stdout = &b
+	defer func() { Stdout = os.Stdout }()
+
+	if _, err := testEval(`print("a", 1, [true, "b"]); print()`); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "a 1 [true, \"b\"]\n\n"
+	if b.String() != expected {
+		t.Errorf("wrong output. expected=%q, got=%q", expected, b.String())
+	}
+}
+
 func TestArrayLiterals(t *testing.T) {
 	tests := []test{
 		{"[1, 2 * 2, 3 + 3]", []any{1, 4, 6}},
@@ -308,6 +757,155 @@ func TestArrayIndexExpressions(t *testing.T) {
 		{"let a = [1, 2, 3]; a[2];", 3},
 		{"let a = [1, 2, 3]; a[0] + a[1] + a[2];", 6},
 		{"let a = [1, 2, 3]; let i = a[0]; a[i]", 2},
+		{"[1, 2, 3][-1]", 3},
+		{"[1, 2, 3][-3]", 1},
+		{"[1, 2, 3][-4]", "1:1: array index out of range"},
+		{"[1, 2, 3][3]", "1:1: array index out of range"},
+		{"let a = [1, 2]; a[-1] = 5; a", []any{1, 5}},
+	}
+
+	autoTest(t, tests)
+}
+
+func TestStringIndexExpressions(t *testing.T) {
+	tests := []test{
+		{`"abc"[0]`, "a"},
+		{`"abc"[-1]`, "c"},
+		{`"héllo"[1]`, "é"},
+		{`"世界"[1]`, "界"},
+		{`"abc"[3]`, "1:1: string index out of range"},
+		{`"abc"["a"]`, "1:1: string indices must be integers, not 'STRING'"},
+		{`let s = "abc"; s[0] = "x"`, "1:16: strings do not support item assignment"},
+	}
+
+	autoTest(t, tests)
+}
+
+func TestSliceExpressions(t *testing.T) {
+	tests := []test{
+		{"[0, 1, 2, 3, 4][1:3]", []any{1, 2}},
+		{"[0, 1, 2, 3, 4][3:]", []any{3, 4}},
+		{"[0, 1, 2, 3, 4][:2]", []any{0, 1}},
+		{"[0, 1, 2, 3, 4][:]", []any{0, 1, 2, 3, 4}},
+		{"[0, 1, 2, 3, 4][::2]", []any{0, 2, 4}},
+		{"[0, 1, 2, 3, 4][::-1]", []any{4, 3, 2, 1, 0}},
+		{"[0, 1, 2, 3, 4][-2:]", []any{3, 4}},
+		{"[0, 1, 2, 3, 4][-100:100]", []any{0, 1, 2, 3, 4}},
+		{"[0, 1, 2, 3, 4][4:1:-2]", []any{4, 2}},
+		{"[0, 1, 2, 3, 4][3:1]", []any{}},
+		{"[0, 1, 2][1 << 64:]", []any{}},
+		{"let a = [1, 2]; let b = a[:]; b[0] = 9; a", []any{1, 2}},
+		{`"héllo"[1:4]`, "éll"},
+		{`"abc"[::-1]`, "cba"},
+		{`"abc"[5:]`, ""},
+		{"[1, 2][::0]", "1:1: slice step cannot be zero"},
+		{`[1, 2]["a":]`, "1:8: slice indices must be integers, not 'STRING'"},
+		{"5[1:]", "1:1: slice operator not supported: 'INTEGER'"},
+	}
+
+	autoTest(t, tests)
+}
+
+func TestSliceAssignment(t *testing.T) {
+	tests := []test{
+		{"let a = [0, 1, 2, 3]; a[1:3] = [7, 8, 9]; a", []any{0, 7, 8, 9, 3}},
+		{"let a = [0, 1, 2, 3]; a[1:3] = []; a", []any{0, 3}},
+		{"let a = [0, 1]; a[2:] = [2, 3]; a", []any{0, 1, 2, 3}},
+		{"let a = [0, 1, 2, 3]; a[::2] = [5, 6]; a", []any{5, 1, 6, 3}},
+		{"let a = [1, 2, 3]; a[::-1] = a; a", []any{3, 2, 1}},
+		{"let a = [1, 2]; a[1:] += [3]; a", []any{1, 2, 3}},
+		{"let a = [0, 1, 2, 3]; a[::2] = [5]", "1:23: cannot assign 1 elements to a slice of 2 elements with step 2"},
+		{"let a = [0, 1]; a[:] = 5", "1:17: can only assign an array to a slice, not 'INTEGER'"},
+		{`let s = "abc"; s[1:] = "x"`, "1:16: strings do not support slice assignment"},
+	}
+
+	autoTest(t, tests)
+}
+
+func TestHashLiterals(t *testing.T) {
+	tests := []test{
+		{`len({})`, 0},
+		{`len({"a": 1, "b": 2, "a": 3})`, 2},
+		{`keys({"one": 1, 2: 2, true: 3})`, []any{"one", 2, true}},
+		{`let k = "two"; values({"one": 10 - 9, k: 1 + 1, "thr" + "ee": 6 / 2})`, []any{1, 2, 3}},
+		{`{[1]: 2}`, "1:2: unhashable type: 'ARRAY'"},
+	}
+
+	autoTest(t, tests)
+}
+
+func TestHashIndexExpressions(t *testing.T) {
+	tests := []test{
+		{`{"foo": 5}["foo"]`, 5},
+		{`{"foo": 5}["bar"]`, nil},
+		{`let key = "foo"; {"foo": 5}[key]`, 5},
+		{`{5: 5}[5]`, 5},
+		{`{true: 5}[true]`, 5},
+		{`{"foo": 5}[[]]`, "1:1: unhashable type: 'ARRAY'"},
+		{`let h = {}; h["a"] = 1; h["b"] = 2; h["a"] += 10; values(h)`, []any{11, 2}},
+		{`let h = {"a": 1}; has(h, "a")`, true},
+		{`let h = {"a": 1}; has(h, "b")`, false},
+		{`let h = {"a": 1, "b": 2}; delete(h, "a")`, 1},
+		{`let h = {"a": 1, "b": 2}; delete(h, "a"); keys(h)`, []any{"b"}},
+		{`let h = {"a": 1}; delete(h, "b")`, nil},
+		{`keys([])`, "1:1: argument to 'keys' must be 'HASH', got 'ARRAY'"},
+	}
+
+	autoTest(t, tests)
+}
+
+func TestCompoundAssignment(t *testing.T) {
+	tests := []test{
+		{"let a = 5; a += 3; a", 8},
+		{"let a = 5; a -= 3; a", 2},
+		{"let a = 5; a *= 3; a", 15},
+		{"let a = 7; a /= 2; a", 3},
+		{"let a = 7.0; a /= 2; a", 3.5},
+		{"let a = 7; a %= 4; a", 3},
+		{"let a = 12; a &= 10; a", 8},
+		{"let a = 1; a |= 6; a", 7},
+		{"let a = 7; a ^= 2; a", 5},
+		{"let a = 1; a <<= 4; a", 16},
+		{"let a = 16; a >>= 2; a", 4},
+		{`let s = "ab"; s *= 2; s`, "abab"},
+		{"let a = [1, 2]; a[1] *= 10; a", []any{1, 20}},
+		{`let h = {"n": 1}; h["n"] <<= 3; h["n"]`, 8},
+		{"let a = [0, 0]; let n = 0; let f = fn() { n += 1; 1 }; a[f()] += 5; [n, a[1]]", []any{1, 5}},
+		{"let a = [1]; let n = 0; let f = fn() { n += 1; 0 }; ++a[f()]; [n, a[0]]", []any{1, 2}},
+		{"let x = 1; let f = fn() { x = 100; return 1 }; x += f(); x", 2},
+		{`let h = {"n": 1}; let f = fn() { h["n"] = 100; return 1 }; h["n"] += f(); h["n"]`, 2},
+		{"let a = [1]; let g = fn() { append(a, 2); a[0] = 50; return 1 }; a[0] += g(); a", []any{2, 2}},
+		{"let a = [1, 2]; let g = fn() { pop(a); 1 }; a[1] += g()", "1:45: array index out of range"},
+		{"let a = 1; a += \"x\"", "1:12: '+' not supported between 'INTEGER' and 'STRING'"},
+		{"b += 1", "1:1: name 'b' is not defined"},
+	}
+
+	autoTest(t, tests)
+}
+
+func TestPostfixIncAndDec(t *testing.T) {
+	tests := []test{
+		{"let a = 5; a++", 5},
+		{"let a = 5; a++; a", 6},
+		{"let a = 5; a--", 5},
+		{"let a = 5; a--; a", 4},
+		{"let a = 5; [a++, a++, a]", []any{5, 6, 7}},
+		{"let a = [1, 2]; [a[1]++, a]", []any{2, []any{1, 3}}},
+		{`let h = {"n": 1}; [h["n"]--, h["n"]]`, []any{1, 0}},
+		{"let a = [0]; let n = 0; let f = fn() { n += 1; 0 }; a[f()]++; [n, a[0]]", []any{1, 1}},
+		{"let s = 0; for (let i = 0; i < 4; i++) { s += i; } s", 6},
+		{`let s = "a"; s++`, "1:14: '+' not supported between 'STRING' and 'INTEGER'"},
+	}
+
+	autoTest(t, tests)
+}
+
+func TestErrorPosition(t *testing.T) {
+	tests := []test{
+		{"foo", "1:1: name 'foo' is not defined"},
+		{"let a = 1;\n  a + b", "2:7: name 'b' is not defined"},
+		{`1 + "a"`, "1:1: '+' not supported between 'INTEGER' and 'STRING'"},
+		{"let f = fn() {\n  1 / 0\n}; f()", "2:3: division by zero"},
 	}
 
 	autoTest(t, tests)
@@ -342,6 +940,10 @@ func testObject(t *testing.T, obj object.Object, expected any) {
 		testIntegerObject(t, obj, e)
 	case string:
 		testStringObject(t, obj, e)
+	case *big.Int:
+		testBigIntegerObject(t, obj, e)
+	case float64:
+		testFloatObject(t, obj, e)
 	case bool:
 		testBooleanObject(t, obj, e)
 	case []any:
@@ -365,6 +967,35 @@ func testIntegerObject(t *testing.T, obj object.Object, expected int64) {
 	}
 }
 
+func testBigIntegerObject(t *testing.T, obj object.Object, expected *big.Int) {
+	res, ok := obj.(*object.BigInteger)
+	if !ok {
+		t.Errorf("object is not BigInteger. got=%T (%v)", obj, obj)
+		return
+	}
+
+	if res.Value.Cmp(expected) != 0 {
+		t.Errorf("object has wrong value. got=%s, want=%s", res.Value, expected)
+	}
+}
+
+func bigInt(s string) *big.Int {
+	v, _ := new(big.Int).SetString(s, 10)
+	return v
+}
+
+func testFloatObject(t *testing.T, obj object.Object, expected float64) {
+	res, ok := obj.(*object.Float)
+	if !ok {
+		t.Errorf("object is not Float. got=%T (%v)", obj, obj)
+		return
+	}
+
+	if res.Value != expected {
+		t.Errorf("object has wrong value. got=%g, want=%g", res.Value, expected)
+	}
+}
+
 func testBooleanObject(t *testing.T, obj object.Object, expected bool) {
 	res, ok := obj.(*object.Boolean)
 	if !ok {
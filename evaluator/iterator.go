@@ -0,0 +1,89 @@
+package evaluator
+
+import (
+	"fmt"
+	"go-interpreter/object"
+	"math"
+)
+
+// iterator returns the next key and value of a collection, and false once it
+// is exhausted.
+type iterator func() (key, value object.Object, ok bool)
+
+// newIterator iterates the indices and elements of an array, the rune
+// indices and runes of a string, the keys and values of a hash, and the
+// indices and values of a range.
+func newIterator(obj object.Object) (iterator, error) {
+	i := int64(-1)
+	next := func() object.Object {
+		i++
+		return object.NewInteger(i)
+	}
+
+	switch obj := obj.(type) {
+	case *object.Array:
+		return func() (object.Object, object.Object, bool) {
+			if i+1 >= int64(len(obj.Elements)) {
+				return nil, nil, false
+			}
+			key := next()
+			return key, obj.Elements[i], true
+		}, nil
+
+	case *object.String:
+		runes := []rune(obj.Value)
+		return func() (object.Object, object.Object, bool) {
+			if i+1 >= int64(len(runes)) {
+				return nil, nil, false
+			}
+			key := next()
+			return key, object.NewString(string(runes[i])), true
+		}, nil
+
+	case *object.Hash:
+		// Iterate the keys present when the loop started, skipping the ones
+		// deleted since.
+		keys := append([]object.HashKey{}, obj.Keys...)
+		return func() (object.Object, object.Object, bool) {
+			for i+1 < int64(len(keys)) {
+				next()
+				if pair, ok := obj.Pairs[keys[i]]; ok {
+					return pair.Key, pair.Value, true
+				}
+			}
+			return nil, nil, false
+		}, nil
+
+	case *object.Range:
+		v, done := obj.Start, false
+		return func() (object.Object, object.Object, bool) {
+			if done || obj.Step > 0 && v >= obj.Stop || obj.Step < 0 && v <= obj.Stop {
+				return nil, nil, false
+			}
+			value := object.NewInteger(v)
+			// Stop rather than overflow past the ends of int64.
+			if obj.Step > 0 && v > math.MaxInt64-obj.Step || obj.Step < 0 && v < math.MinInt64-obj.Step {
+				done = true
+			}
+			v += obj.Step
+			return next(), value, true
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("'%s' is not iterable", obj.Type())
+	}
+}
+
+// newElementIterator is newIterator for the uses taking a single element per
+// step, the one variable for-in loop and spreading. The elements are the
+// values, or the keys for hashes.
+func newElementIterator(obj object.Object) (iterator, error) {
+	next, err := newIterator(obj)
+	if _, ok := obj.(*object.Hash); !ok || err != nil {
+		return next, err
+	}
+	return func() (object.Object, object.Object, bool) {
+		key, _, ok := next()
+		return nil, key, ok
+	}, nil
+}
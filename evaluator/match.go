@@ -0,0 +1,159 @@
+package evaluator
+
+import (
+	"go-interpreter/ast"
+	"go-interpreter/object"
+)
+
+// patternTypes are the type names a type pattern can test for.
+var patternTypes = map[object.ObjectType]bool{
+	object.INTEGER_OBJ:  true,
+	object.FLOAT_OBJ:    true,
+	object.BOOLEAN_OBJ:  true,
+	object.STRING_OBJ:   true,
+	object.FUNCTION_OBJ: true,
+	object.BUILTIN_OBJ:  true,
+	object.ARRAY_OBJ:    true,
+	object.HASH_OBJ:     true,
+	object.RANGE_OBJ:    true,
+	object.NULL_OBJ:     true,
+}
+
+// evalMatchExpression evaluates the body of the first case whose pattern
+// matches the subject and whose guard, if any, is truthy. The guard and body
+// run in a new scope holding the names bound by the pattern. It is an error
+// for no case to match.
+func evalMatchExpression(me *ast.MatchExpression, env *object.Environment) (object.Object, error) {
+	subject, err := Eval(me.Subject, env)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range me.Cases {
+		caseEnv := object.NewEnclosedEnvironment(env)
+		matched, err := matchPattern(c.Pattern, subject, caseEnv)
+		if err != nil {
+			return nil, err
+		}
+		if !matched {
+			continue
+		}
+
+		if c.Guard != nil {
+			guard, err := Eval(c.Guard, caseEnv)
+			if err != nil {
+				return nil, err
+			}
+			if !isTruthy(guard) {
+				continue
+			}
+		}
+		return evalStatements(c.Body.Stmts, caseEnv)
+	}
+
+	return nil, newError(me.Subject, "no case matches %s", subject.Inspect())
+}
+
+// matchPattern reports whether obj matches pat, binding the names in pat to
+// the matching parts of obj in env. A literal matches the values it is equal
+// to with '==' and no value it cannot be compared with.
+func matchPattern(pat ast.Pattern, obj object.Object, env *object.Environment) (bool, error) {
+	switch pat := pat.(type) {
+	case *ast.WildcardPattern:
+		return true, nil
+
+	case *ast.BindingPattern:
+		env.Set(pat.Name.Value, obj)
+		return true, nil
+
+	case *ast.LiteralPattern:
+		val, err := Eval(pat.Value, env)
+		if err != nil {
+			return false, err
+		}
+		res, err := eq(obj, val)
+		return err == nil && res == object.TRUE, nil
+
+	case *ast.ArrayPattern:
+		return matchArrayPattern(pat, obj, env)
+
+	case *ast.HashPattern:
+		return matchHashPattern(pat, obj, env)
+
+	case *ast.TypePattern:
+		tp := object.ObjectType(pat.Type.Value)
+		if !patternTypes[tp] {
+			return false, newError(pat.Type, "unknown type '%s'", pat.Type.Value)
+		}
+		if obj.Type() != tp {
+			return false, nil
+		}
+		return matchPattern(pat.Pattern, obj, env)
+	}
+
+	return false, newError(pat, "unknown pattern: %T", pat)
+}
+
+func matchArrayPattern(pat *ast.ArrayPattern, obj object.Object, env *object.Environment) (bool, error) {
+	arr, ok := obj.(*object.Array)
+	if !ok {
+		return false, nil
+	}
+
+	n := len(pat.Elements)
+	if len(arr.Elements) < n || pat.Rest == nil && len(arr.Elements) != n {
+		return false, nil
+	}
+
+	for i, elem := range pat.Elements {
+		if matched, err := matchPattern(elem, arr.Elements[i], env); !matched || err != nil {
+			return false, err
+		}
+	}
+
+	if pat.Rest != nil {
+		rest := make([]object.Object, len(arr.Elements)-n)
+		copy(rest, arr.Elements[n:])
+		return matchPattern(pat.Rest, object.NewArray(rest), env)
+	}
+	return true, nil
+}
+
+func matchHashPattern(pat *ast.HashPattern, obj object.Object, env *object.Environment) (bool, error) {
+	h, ok := obj.(*object.Hash)
+	if !ok {
+		return false, nil
+	}
+
+	matchedKeys := map[object.HashKey]bool{}
+	for i, k := range pat.Keys {
+		key, err := Eval(k, env)
+		if err != nil {
+			return false, err
+		}
+		hashable, err := checkIsHashable(key)
+		if err != nil {
+			return false, newError(k, "%s", err)
+		}
+
+		val, ok := h.Get(hashable)
+		if !ok {
+			return false, nil
+		}
+		if matched, err := matchPattern(pat.Values[i], val, env); !matched || err != nil {
+			return false, err
+		}
+		matchedKeys[hashable.HashKey()] = true
+	}
+
+	if pat.Rest != nil {
+		rest := object.NewHash()
+		for _, k := range h.Keys {
+			if p, ok := h.Pairs[k]; ok && !matchedKeys[k] {
+				rest.Set(p.Key.(object.Hashable), p.Value)
+			}
+		}
+		return matchPattern(pat.Rest, rest, env)
+	}
+	return true, nil
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"go-interpreter/object"
+	"math"
+	"math/big"
 	"strings"
 )
 
@@ -38,7 +40,12 @@ var (
 func neg(obj object.Object) (object.Object, error) {
 	switch obj.Type() {
 	case object.INTEGER_OBJ, object.BOOLEAN_OBJ:
-		return object.NewInteger(-objectToInteger(obj)), nil
+		if b, ok := obj.(*object.BigInteger); ok {
+			return object.IntegerFromBig(new(big.Int).Neg(b.Value)), nil
+		}
+		return subInt(0, objectToInteger(obj)), nil
+	case object.FLOAT_OBJ:
+		return object.NewFloat(-obj.(*object.Float).Value), nil
 	default:
 		return nil, fmt.Errorf("bad operand type for unary -: '%s'", obj.Type())
 	}
@@ -49,11 +56,24 @@ func not(obj object.Object) (object.Object, error) {
 }
 
 func bitNOT(obj object.Object) (object.Object, error) {
+	if obj.Type() == object.FLOAT_OBJ {
+		return nil, fmt.Errorf("bad operand type for unary ~: '%s'", obj.Type())
+	}
+	if b, ok := obj.(*object.BigInteger); ok {
+		return object.IntegerFromBig(new(big.Int).Not(b.Value)), nil
+	}
 	return object.NewInteger(^objectToInteger(obj)), nil
 }
 
 // binary operator
 func lt(l, r object.Object) (object.Object, error) {
+	if x, y, ok := floatOperands(l, r); ok {
+		return object.NewBoolean(x < y), nil
+	}
+	if x, y, ok := bigOperands(l, r); ok {
+		return object.NewBoolean(x.Cmp(y) < 0), nil
+	}
+
 	switch l.Type() {
 	case object.INTEGER_OBJ, object.BOOLEAN_OBJ:
 		switch r.Type() {
@@ -80,6 +100,10 @@ func gt(l, r object.Object) (object.Object, error) {
 }
 
 func le(l, r object.Object) (object.Object, error) {
+	if x, y, ok := floatOperands(l, r); ok {
+		return object.NewBoolean(x <= y), nil
+	}
+
 	res, err := gt(l, r)
 	if err != nil {
 		return nil, fmt.Errorf("'<=' not supported between '%s' and '%s'", l.Type(), r.Type())
@@ -88,6 +112,10 @@ func le(l, r object.Object) (object.Object, error) {
 }
 
 func ge(l, r object.Object) (object.Object, error) {
+	if x, y, ok := floatOperands(l, r); ok {
+		return object.NewBoolean(x >= y), nil
+	}
+
 	res, err := lt(l, r)
 	if err != nil {
 		return nil, fmt.Errorf("'>=' not supported between '%s' and '%s'", l.Type(), r.Type())
@@ -100,6 +128,10 @@ func eq(l, r object.Object) (object.Object, error) {
 		return object.TRUE, nil
 	}
 
+	if x, y, ok := floatOperands(l, r); ok {
+		return object.NewBoolean(x == y), nil
+	}
+
 	defaultErr := fmt.Errorf("'==' not supported between '%s' and '%s'", l.Type(), r.Type())
 	if res, err := lt(l, r); err != nil {
 		return nil, defaultErr
@@ -124,11 +156,18 @@ func neq(l, r object.Object) (object.Object, error) {
 }
 
 func add(l, r object.Object) (object.Object, error) {
+	if x, y, ok := floatOperands(l, r); ok {
+		return object.NewFloat(x + y), nil
+	}
+	if x, y, ok := bigOperands(l, r); ok {
+		return object.IntegerFromBig(new(big.Int).Add(x, y)), nil
+	}
+
 	switch l.Type() {
 	case object.INTEGER_OBJ, object.BOOLEAN_OBJ:
 		switch r.Type() {
 		case object.INTEGER_OBJ, object.BOOLEAN_OBJ:
-			return object.NewInteger(objectToInteger(l) + objectToInteger(r)), nil
+			return addInt(objectToInteger(l), objectToInteger(r)), nil
 		}
 
 	case object.STRING_OBJ:
@@ -156,11 +195,18 @@ func add(l, r object.Object) (object.Object, error) {
 }
 
 func sub(l, r object.Object) (object.Object, error) {
+	if x, y, ok := floatOperands(l, r); ok {
+		return object.NewFloat(x - y), nil
+	}
+	if x, y, ok := bigOperands(l, r); ok {
+		return object.IntegerFromBig(new(big.Int).Sub(x, y)), nil
+	}
+
 	switch l.Type() {
 	case object.INTEGER_OBJ, object.BOOLEAN_OBJ:
 		switch r.Type() {
 		case object.INTEGER_OBJ, object.BOOLEAN_OBJ:
-			return object.NewInteger(objectToInteger(l) - objectToInteger(r)), nil
+			return subInt(objectToInteger(l), objectToInteger(r)), nil
 		}
 	}
 
@@ -168,16 +214,26 @@ func sub(l, r object.Object) (object.Object, error) {
 }
 
 func mul(l, r object.Object) (object.Object, error) {
+	if x, y, ok := floatOperands(l, r); ok {
+		return object.NewFloat(x * y), nil
+	}
+	if x, y, ok := bigOperands(l, r); ok {
+		return object.IntegerFromBig(new(big.Int).Mul(x, y)), nil
+	}
+
 	switch l.Type() {
 	case object.INTEGER_OBJ, object.BOOLEAN_OBJ:
 		switch r.Type() {
 		case object.INTEGER_OBJ, object.BOOLEAN_OBJ:
-			return object.NewInteger(objectToInteger(l) * objectToInteger(r)), nil
+			return mulInt(objectToInteger(l), objectToInteger(r)), nil
 		}
 
 	case object.STRING_OBJ:
 		switch r.Type() {
 		case object.INTEGER_OBJ, object.BOOLEAN_OBJ:
+			if _, ok := r.(*object.BigInteger); ok {
+				return nil, errors.New("repeat count too large")
+			}
 			var b strings.Builder
 			s := l.(*object.String).Value
 			for i := 0; i < int(objectToInteger(r)); i++ {
@@ -189,6 +245,9 @@ func mul(l, r object.Object) (object.Object, error) {
 	case object.ARRAY_OBJ:
 		switch r.Type() {
 		case object.INTEGER_OBJ, object.BOOLEAN_OBJ:
+			if _, ok := r.(*object.BigInteger); ok {
+				return nil, errors.New("repeat count too large")
+			}
 			objs := l.(*object.Array).Elements
 			newObjs := []object.Object{}
 			for i := 0; i < int(objectToInteger(r)); i++ {
@@ -202,6 +261,19 @@ func mul(l, r object.Object) (object.Object, error) {
 }
 
 func div(l, r object.Object) (object.Object, error) {
+	if x, y, ok := floatOperands(l, r); ok {
+		if y == 0 {
+			return nil, errors.New("float division by zero")
+		}
+		return object.NewFloat(x / y), nil
+	}
+	if x, y, ok := bigOperands(l, r); ok {
+		if y.Sign() == 0 {
+			return nil, errors.New("division by zero")
+		}
+		return object.IntegerFromBig(new(big.Int).Quo(x, y)), nil
+	}
+
 	switch l.Type() {
 	case object.INTEGER_OBJ, object.BOOLEAN_OBJ:
 		switch r.Type() {
@@ -210,7 +282,10 @@ func div(l, r object.Object) (object.Object, error) {
 			if divisor == 0 {
 				return nil, errors.New("division by zero")
 			}
-			return object.NewInteger(objectToInteger(l) / divisor), nil
+			if dividend := objectToInteger(l); dividend != math.MinInt64 || divisor != -1 {
+				return object.NewInteger(dividend / divisor), nil
+			}
+			return object.IntegerFromBig(new(big.Int).Neg(big.NewInt(math.MinInt64))), nil
 		}
 	}
 
@@ -218,6 +293,28 @@ func div(l, r object.Object) (object.Object, error) {
 }
 
 func mod(l, r object.Object) (object.Object, error) {
+	if x, y, ok := floatOperands(l, r); ok {
+		if y == 0 {
+			return nil, errors.New("float modulo by zero")
+		}
+		// Like the integer case, the result takes the sign of the divisor.
+		res := math.Mod(x, y)
+		if res != 0 && (res < 0) != (y < 0) {
+			res += y
+		}
+		return object.NewFloat(res), nil
+	}
+	if x, y, ok := bigOperands(l, r); ok {
+		if y.Sign() == 0 {
+			return nil, errors.New("integer division or modulo by zero")
+		}
+		res := new(big.Int).Rem(x, y)
+		if res.Sign() != 0 && (res.Sign() < 0) != (y.Sign() < 0) {
+			res.Add(res, y)
+		}
+		return object.IntegerFromBig(res), nil
+	}
+
 	switch l.Type() {
 	case object.INTEGER_OBJ, object.BOOLEAN_OBJ:
 		switch r.Type() {
@@ -226,7 +323,11 @@ func mod(l, r object.Object) (object.Object, error) {
 			if modulus == 0 {
 				return nil, errors.New("integer division or modulo by zero")
 			}
-			return object.NewInteger(((objectToInteger(l) % modulus) + modulus) % modulus), nil
+			res := objectToInteger(l) % modulus
+			if res != 0 && (res < 0) != (modulus < 0) {
+				res += modulus
+			}
+			return object.NewInteger(res), nil
 		}
 	}
 
@@ -234,6 +335,10 @@ func mod(l, r object.Object) (object.Object, error) {
 }
 
 func bitAND(l, r object.Object) (object.Object, error) {
+	if x, y, ok := bigOperands(l, r); ok {
+		return object.IntegerFromBig(new(big.Int).And(x, y)), nil
+	}
+
 	switch l.Type() {
 	case object.INTEGER_OBJ, object.BOOLEAN_OBJ:
 		switch r.Type() {
@@ -246,6 +351,10 @@ func bitAND(l, r object.Object) (object.Object, error) {
 }
 
 func bitOR(l, r object.Object) (object.Object, error) {
+	if x, y, ok := bigOperands(l, r); ok {
+		return object.IntegerFromBig(new(big.Int).Or(x, y)), nil
+	}
+
 	switch l.Type() {
 	case object.INTEGER_OBJ, object.BOOLEAN_OBJ:
 		switch r.Type() {
@@ -258,6 +367,10 @@ func bitOR(l, r object.Object) (object.Object, error) {
 }
 
 func bitXOR(l, r object.Object) (object.Object, error) {
+	if x, y, ok := bigOperands(l, r); ok {
+		return object.IntegerFromBig(new(big.Int).Xor(x, y)), nil
+	}
+
 	switch l.Type() {
 	case object.INTEGER_OBJ, object.BOOLEAN_OBJ:
 		switch r.Type() {
@@ -274,11 +387,18 @@ func shl(l, r object.Object) (object.Object, error) {
 	case object.INTEGER_OBJ, object.BOOLEAN_OBJ:
 		switch r.Type() {
 		case object.INTEGER_OBJ, object.BOOLEAN_OBJ:
-			shift := objectToInteger(r)
-			if shift < 0 {
-				return nil, errors.New("negative shift amount")
+			shift, err := shiftAmount(r)
+			if err != nil {
+				return nil, err
+			}
+			if x, ok := l.(*object.BigInteger); ok {
+				return object.IntegerFromBig(new(big.Int).Lsh(x.Value, shift)), nil
+			}
+			x := objectToInteger(l)
+			if shift < 63 && x<<shift>>shift == x {
+				return object.NewInteger(x << shift), nil
 			}
-			return object.NewInteger(objectToInteger(l) << shift), nil
+			return object.IntegerFromBig(new(big.Int).Lsh(big.NewInt(x), shift)), nil
 		}
 	}
 
@@ -290,9 +410,15 @@ func shr(l, r object.Object) (object.Object, error) {
 	case object.INTEGER_OBJ, object.BOOLEAN_OBJ:
 		switch r.Type() {
 		case object.INTEGER_OBJ, object.BOOLEAN_OBJ:
-			shift := objectToInteger(r)
-			if shift < 0 {
-				return nil, errors.New("negative shift amount")
+			shift, err := shiftAmount(r)
+			if err != nil {
+				return nil, err
+			}
+			if x, ok := l.(*object.BigInteger); ok {
+				return object.IntegerFromBig(new(big.Int).Rsh(x.Value, shift)), nil
+			}
+			if shift > 63 {
+				shift = 63
 			}
 			return object.NewInteger(objectToInteger(l) >> shift), nil
 		}
@@ -301,6 +427,72 @@ func shr(l, r object.Object) (object.Object, error) {
 	return nil, fmt.Errorf("'>>' not supported between '%s' and '%s'", l.Type(), r.Type())
 }
 
+func shiftAmount(obj object.Object) (uint, error) {
+	if _, ok := obj.(*object.BigInteger); ok {
+		return 0, errors.New("shift count too large")
+	}
+	shift := objectToInteger(obj)
+	if shift < 0 {
+		return 0, errors.New("negative shift amount")
+	}
+	return uint(shift), nil
+}
+
+// addInt, subInt and mulInt compute x op y, promoting the result to a big
+// integer when it overflows int64.
+func addInt(x, y int64) object.Object {
+	if res := x + y; (res > x) == (y > 0) {
+		return object.NewInteger(res)
+	}
+	return object.IntegerFromBig(new(big.Int).Add(big.NewInt(x), big.NewInt(y)))
+}
+
+func subInt(x, y int64) object.Object {
+	if res := x - y; (res < x) == (y > 0) {
+		return object.NewInteger(res)
+	}
+	return object.IntegerFromBig(new(big.Int).Sub(big.NewInt(x), big.NewInt(y)))
+}
+
+func mulInt(x, y int64) object.Object {
+	res := x * y
+	if x == 0 || (res/x == y && !(x == -1 && y == math.MinInt64)) {
+		return object.NewInteger(res)
+	}
+	return object.IntegerFromBig(new(big.Int).Mul(big.NewInt(x), big.NewInt(y)))
+}
+
+// bigOperands converts l and r to big integers if both are integers and at
+// least one of them is already big; otherwise the int64 paths apply.
+func bigOperands(l, r object.Object) (*big.Int, *big.Int, bool) {
+	_, lbig := l.(*object.BigInteger)
+	_, rbig := r.(*object.BigInteger)
+	if !lbig && !rbig {
+		return nil, nil, false
+	}
+
+	x, ok := objectToBig(l)
+	if !ok {
+		return nil, nil, false
+	}
+	y, ok := objectToBig(r)
+	if !ok {
+		return nil, nil, false
+	}
+	return x, y, true
+}
+
+func objectToBig(obj object.Object) (*big.Int, bool) {
+	switch obj := obj.(type) {
+	case *object.BigInteger:
+		return obj.Value, true
+	case *object.Integer, *object.Boolean:
+		return big.NewInt(objectToInteger(obj)), true
+	default:
+		return nil, false
+	}
+}
+
 func objectToInteger(obj object.Object) int64 {
 	switch obj := obj.(type) {
 	case *object.Integer:
@@ -315,16 +507,54 @@ func objectToInteger(obj object.Object) int64 {
 	}
 }
 
+// floatOperands converts l and r to float64 if both are numbers and at least
+// one of them is a float; integer-only arithmetic stays exact.
+func floatOperands(l, r object.Object) (float64, float64, bool) {
+	if l.Type() != object.FLOAT_OBJ && r.Type() != object.FLOAT_OBJ {
+		return 0, 0, false
+	}
+
+	x, ok := objectToFloat(l)
+	if !ok {
+		return 0, 0, false
+	}
+	y, ok := objectToFloat(r)
+	if !ok {
+		return 0, 0, false
+	}
+	return x, y, true
+}
+
+func objectToFloat(obj object.Object) (float64, bool) {
+	switch obj := obj.(type) {
+	case *object.Float:
+		return obj.Value, true
+	case *object.Integer, *object.Boolean:
+		return float64(objectToInteger(obj)), true
+	case *object.BigInteger:
+		f, _ := new(big.Float).SetInt(obj.Value).Float64()
+		return f, true
+	default:
+		return 0, false
+	}
+}
+
 func isTruthy(obj object.Object) bool {
 	switch obj := obj.(type) {
 	case *object.Boolean:
 		return obj.Value
 	case *object.Integer:
 		return obj.Value != 0
+	case *object.Float:
+		return obj.Value != 0
 	case *object.String:
 		return obj.Value != ""
 	case *object.Array:
 		return len(obj.Elements) != 0
+	case *object.Hash:
+		return len(obj.Pairs) != 0
+	case *object.Range:
+		return obj.Len().Sign() != 0
 	case *object.Null:
 		return false
 	default:
@@ -0,0 +1,42 @@
+package evaluator
+
+import (
+	"go-interpreter/object"
+	"go-interpreter/token"
+)
+
+// signal is a non-local transfer of control, 'return', 'break' or 'continue'.
+// It is passed up as an error so that every enclosing expression and
+// statement unwinds without checking for it, until the function call or loop
+// it targets catches it.
+type signal interface {
+	error
+	signal()
+}
+
+type returnSignal struct{ value object.Object }
+
+func (rs *returnSignal) signal()       {}
+func (rs *returnSignal) Error() string { return "'return' outside function" }
+
+type branchSignal struct {
+	tok   token.TokenType // token.BREAK or token.CONTINUE
+	label string          // empty for the innermost loop
+}
+
+func (bs *branchSignal) signal() {}
+func (bs *branchSignal) Error() string {
+	if bs.tok == token.BREAK {
+		return "'break' outside loop"
+	}
+	return "'continue' outside loop"
+}
+
+// catchReturn returns the value carried by err if it is a 'return', and err
+// otherwise.
+func catchReturn(res object.Object, err error) (object.Object, error) {
+	if rs, ok := err.(*returnSignal); ok {
+		return rs.value, nil
+	}
+	return res, err
+}
@@ -1,24 +1,76 @@
 package lexer
 
-import "go-interpreter/token"
+import (
+	"fmt"
+	"go-interpreter/token"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
 
 type Lexer struct {
+	filename     string
 	input        string
+	base         int  // offset of input within the file
 	position     int  // points to current char
 	readPosition int  // after current char
-	ch           byte // current char
+	ch           rune // current char
+	line, column int  // position of current char, the column counting runes
+
+	errors []error // one for every ILLEGAL token returned so far
+	errMsg string  // why the token being read is ILLEGAL
 }
 
 func NewLexer(input string) *Lexer {
-	l := &Lexer{input: input}
+	return NewFileLexer("", input)
+}
+
+func NewFileLexer(filename, input string) *Lexer {
+	return NewLexerAt(token.Position{Filename: filename, Line: 1, Column: 1}, input)
+}
+
+// NewLexerAt returns a lexer whose positions start at pos, so that input can
+// continue an earlier buffer of the same file.
+func NewLexerAt(pos token.Position, input string) *Lexer {
+	l := &Lexer{
+		filename: pos.Filename,
+		input:    input,
+		base:     pos.Offset,
+		line:     pos.Line,
+		column:   pos.Column - 1,
+	}
 	l.readChar()
+	l.skipShebang()
 	return l
 }
 
 func (l *Lexer) NextToken() *token.Token {
-	tok := &token.Token{}
+	comments := l.skipWhitespace()
+
+	pos := l.pos()
+	l.errMsg = ""
+	tok := l.readToken()
+	tok.Pos, tok.End = pos, l.pos()
+	tok.Comments = comments
+
+	if tok.Type == token.ILLEGAL {
+		msg := l.errMsg
+		if msg == "" {
+			msg = fmt.Sprintf("illegal character %q", tok.Literal)
+		}
+		l.errors = append(l.errors, token.NewError(tok.Pos, tok.End, msg))
+	}
+	return tok
+}
 
-	l.skipWhitespace()
+// Errors returns the errors describing the ILLEGAL tokens read so far.
+func (l *Lexer) Errors() []error {
+	return l.errors
+}
+
+func (l *Lexer) readToken() *token.Token {
+	tok := &token.Token{}
 
 	switch s := string(l.ch); l.ch {
 	case '=':
@@ -36,21 +88,34 @@ func (l *Lexer) NextToken() *token.Token {
 			tok = token.NewToken(token.BANG, s)
 		}
 	case '&':
-		if l.peekChar() == '&' {
+		switch l.peekChar() {
+		case '&':
 			l.readChar()
 			tok = token.NewToken(token.LOGICAL_AND, s+string(l.ch))
-		} else {
+		case '=':
+			l.readChar()
+			tok = token.NewToken(token.BITWISE_AND_ASSIGN, s+string(l.ch))
+		default:
 			tok = token.NewToken(token.BITWISE_AND, s)
 		}
 	case '|':
-		if l.peekChar() == '|' {
+		switch l.peekChar() {
+		case '|':
 			l.readChar()
 			tok = token.NewToken(token.LOGICAL_OR, s+string(l.ch))
-		} else {
+		case '=':
+			l.readChar()
+			tok = token.NewToken(token.BITWISE_OR_ASSIGN, s+string(l.ch))
+		default:
 			tok = token.NewToken(token.BITWISE_OR, s)
 		}
 	case '^':
-		tok = token.NewToken(token.BITWISE_XOR, s)
+		if l.peekChar() == '=' {
+			l.readChar()
+			tok = token.NewToken(token.BITWISE_XOR_ASSIGN, s+string(l.ch))
+		} else {
+			tok = token.NewToken(token.BITWISE_XOR, s)
+		}
 	case '~':
 		tok = token.NewToken(token.BITWISE_NOT, s)
 	case '+':
@@ -76,16 +141,45 @@ func (l *Lexer) NextToken() *token.Token {
 			tok = token.NewToken(token.MINUS, s)
 		}
 	case '*':
-		tok = token.NewToken(token.ASTERISK, s)
+		if l.peekChar() == '=' {
+			l.readChar()
+			tok = token.NewToken(token.ASTERISK_ASSIGN, s+string(l.ch))
+		} else {
+			tok = token.NewToken(token.ASTERISK, s)
+		}
 	case '/':
-		tok = token.NewToken(token.SLASH, s)
+		if l.peekChar() == '*' {
+			// skipWhitespace leaves only unterminated block comments.
+			p := l.position
+			for l.ch != 0 || l.position < len(l.input) {
+				l.readChar()
+			}
+			l.errMsg = "unterminated comment"
+			return token.NewToken(token.ILLEGAL, l.input[p:l.position])
+		}
+		if l.peekChar() == '=' {
+			l.readChar()
+			tok = token.NewToken(token.SLASH_ASSIGN, s+string(l.ch))
+		} else {
+			tok = token.NewToken(token.SLASH, s)
+		}
 	case '%':
-		tok = token.NewToken(token.MOD, s)
+		if l.peekChar() == '=' {
+			l.readChar()
+			tok = token.NewToken(token.MOD_ASSIGN, s+string(l.ch))
+		} else {
+			tok = token.NewToken(token.MOD, s)
+		}
 	case '<':
 		switch l.peekChar() {
 		case '<':
 			l.readChar()
-			tok = token.NewToken(token.SHL, s+string(l.ch))
+			if l.peekChar() == '=' {
+				l.readChar()
+				tok = token.NewToken(token.SHL_ASSIGN, "<<=")
+			} else {
+				tok = token.NewToken(token.SHL, "<<")
+			}
 		case '=':
 			l.readChar()
 			tok = token.NewToken(token.LE, s+string(l.ch))
@@ -96,13 +190,26 @@ func (l *Lexer) NextToken() *token.Token {
 		switch l.peekChar() {
 		case '>':
 			l.readChar()
-			tok = token.NewToken(token.SHR, s+string(l.ch))
+			if l.peekChar() == '=' {
+				l.readChar()
+				tok = token.NewToken(token.SHR_ASSIGN, ">>=")
+			} else {
+				tok = token.NewToken(token.SHR, ">>")
+			}
 		case '=':
 			l.readChar()
 			tok = token.NewToken(token.GE, s+string(l.ch))
 		default:
 			tok = token.NewToken(token.GT, s)
 		}
+	case '.':
+		if l.peekCharAt(1) == '.' && l.peekCharAt(2) == '.' {
+			l.readChar()
+			l.readChar()
+			tok = token.NewToken(token.ELLIPSIS, "...")
+		} else {
+			tok = token.NewToken(token.ILLEGAL, s)
+		}
 	case ',':
 		tok = token.NewToken(token.COMMA, s)
 	case ':':
@@ -121,8 +228,8 @@ func (l *Lexer) NextToken() *token.Token {
 		tok = token.NewToken(token.LBRACKET, s)
 	case ']':
 		tok = token.NewToken(token.RBRACKET, s)
-	case '"':
-		tok = token.NewToken(token.STRING, l.readString())
+	case '"', '`':
+		tok = l.readString()
 	case 0:
 		tok = token.NewToken(token.EOF, "")
 	default:
@@ -131,7 +238,7 @@ func (l *Lexer) NextToken() *token.Token {
 			tok.Type = token.LookupIdent(tok.Literal)
 			return tok
 		} else if isDigit(l.ch) {
-			tok = token.NewToken(token.INT, l.readNumber())
+			tok = token.NewToken(l.readNumber())
 			return tok
 		} else {
 			tok = token.NewToken(token.ILLEGAL, s)
@@ -143,59 +250,322 @@ func (l *Lexer) NextToken() *token.Token {
 }
 
 func (l *Lexer) readChar() {
+	if l.readPosition > len(l.input) {
+		return
+	}
+	if l.ch == '\n' {
+		l.line++
+		l.column = 0
+	}
+	l.column++
+	l.position = l.readPosition
+
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
-	} else {
-		l.ch = l.input[l.readPosition]
+		l.readPosition++
+		return
 	}
-	l.position = l.readPosition
-	l.readPosition++
+
+	r, w := utf8.DecodeRuneInString(l.input[l.readPosition:])
+	l.ch = r
+	l.readPosition += w
+}
+
+func (l *Lexer) pos() token.Position {
+	return token.Position{Filename: l.filename, Offset: l.base + l.position, Line: l.line, Column: l.column}
 }
 
-func (l *Lexer) peekChar() byte {
+func (l *Lexer) peekChar() rune {
 	if l.readPosition >= len(l.input) {
 		return 0
 	}
-	return l.input[l.readPosition]
+	r, _ := utf8.DecodeRuneInString(l.input[l.readPosition:])
+	return r
+}
+
+// peekCharAt returns the byte n bytes ahead of the current char, for looking
+// past ASCII characters.
+func (l *Lexer) peekCharAt(n int) rune {
+	if l.position+n >= len(l.input) {
+		return 0
+	}
+	return rune(l.input[l.position+n])
+}
+
+// skipWhitespace skips whitespace and comments, returning the comments. An
+// unterminated block comment is left for readToken to report.
+func (l *Lexer) skipWhitespace() []token.Comment {
+	var comments []token.Comment
+
+	for {
+		switch {
+		case l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r':
+			l.readChar()
+			continue
+
+		case l.ch == '/' && l.peekChar() == '/':
+			pos, p := l.pos(), l.position
+			for l.ch != '\n' && (l.ch != 0 || l.position < len(l.input)) {
+				l.readChar()
+			}
+			comments = append(comments, token.Comment{Text: l.input[p:l.position], Pos: pos, End: l.pos()})
+			continue
+
+		case l.ch == '/' && l.peekChar() == '*':
+			n, ok := blockCommentLen(l.input[l.position:])
+			if !ok {
+				return comments
+			}
+			pos, p := l.pos(), l.position
+			for l.position < p+n {
+				l.readChar()
+			}
+			comments = append(comments, token.Comment{Text: l.input[p:l.position], Pos: pos, End: l.pos()})
+			continue
+		}
+
+		return comments
+	}
 }
 
-func (l *Lexer) skipWhitespace() {
-	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
+// blockCommentLen returns the length of the block comment s starts with.
+// Block comments nest, so that code containing comments can be commented
+// out; ok is false if the comment is not closed.
+func blockCommentLen(s string) (n int, ok bool) {
+	depth := 0
+	for i := 0; i+1 < len(s); i++ {
+		switch s[i : i+2] {
+		case "/*":
+			depth++
+			i++
+		case "*/":
+			depth--
+			i++
+			if depth == 0 {
+				return i + 1, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// skipShebang skips a leading "#!" interpreter line so that scripts can be
+// made directly executable.
+func (l *Lexer) skipShebang() {
+	if l.base != 0 || !strings.HasPrefix(l.input, "#!") {
+		return
+	}
+	for l.ch != '\n' && l.ch != 0 {
 		l.readChar()
 	}
 }
 
 func (l *Lexer) readIdentifier() string {
 	p := l.position
-	for isLetter(l.ch) {
+	for isLetter(l.ch) || unicode.IsDigit(l.ch) {
 		l.readChar()
 	}
 	return l.input[p:l.position]
 }
 
-func (l *Lexer) readNumber() string {
+// readNumber reads an integer or a float literal such as 1_000, 0xFF, 1.5,
+// 2e10 or 3.0e-2. Malformed literals give an ILLEGAL token.
+func (l *Lexer) readNumber() (token.TokenType, string) {
+	if l.ch == '0' && strings.ContainsRune("xXoObB", l.peekChar()) {
+		return l.readPrefixedNumber()
+	}
+
 	p := l.position
-	for isDigit(l.ch) {
+	var tokType token.TokenType = token.INT
+
+	l.readDigits()
+	if l.ch == '.' && isDigit(l.peekChar()) {
+		tokType = token.FLOAT
 		l.readChar()
+		l.readDigits()
 	}
-	return l.input[p:l.position]
+
+	if l.ch == 'e' || l.ch == 'E' {
+		tokType = token.FLOAT
+		l.readChar()
+		if l.ch == '+' || l.ch == '-' {
+			l.readChar()
+		}
+		if !isDigit(l.ch) {
+			l.errMsg = "exponent has no digits"
+			return token.ILLEGAL, l.input[p:l.position]
+		}
+		l.readDigits()
+	}
+
+	lit := l.input[p:l.position]
+	if !validSeparators(lit) {
+		l.errMsg = "'_' must separate successive digits"
+		return token.ILLEGAL, lit
+	}
+	return tokType, lit
 }
 
-func (l *Lexer) readString() string {
-	p := l.position + 1
+var bases = map[byte]struct {
+	digits, name string
+}{
+	'x': {"0123456789abcdefABCDEF", "hexadecimal"},
+	'o': {"01234567", "octal"},
+	'b': {"01", "binary"},
+}
+
+// readPrefixedNumber reads a hexadecimal, octal or binary integer literal.
+// Any letters and digits directly following belong to the literal, so that
+// e.g. 0b102 is reported as a whole.
+func (l *Lexer) readPrefixedNumber() (token.TokenType, string) {
+	p := l.position
+	base := bases[byte(unicode.ToLower(l.peekChar()))]
+	l.readChar()
+	l.readChar()
+
+	for isLetter(l.ch) || isDigit(l.ch) {
+		l.readChar()
+	}
+	lit := l.input[p:l.position]
+
+	digits := strings.ReplaceAll(lit[2:], "_", "")
+	if digits == "" {
+		l.errMsg = fmt.Sprintf("%s literal has no digits", base.name)
+		return token.ILLEGAL, lit
+	}
+	for _, c := range digits {
+		if !strings.ContainsRune(base.digits, c) {
+			l.errMsg = fmt.Sprintf("invalid digit %q in %s literal", c, base.name)
+			return token.ILLEGAL, lit
+		}
+	}
+	if !validSeparators(lit) {
+		l.errMsg = "'_' must separate successive digits"
+		return token.ILLEGAL, lit
+	}
+
+	return token.INT, lit
+}
+
+// readDigits reads decimal digits and the '_' separators between them.
+func (l *Lexer) readDigits() {
+	for isDigit(l.ch) || l.ch == '_' {
+		l.readChar()
+	}
+}
+
+// validSeparators reports whether every '_' in the number literal lit sits
+// between two digits, or between a base prefix and a digit.
+func validSeparators(lit string) bool {
+	prefixed := len(lit) > 1 && lit[0] == '0' && strings.ContainsRune("xXoObB", rune(lit[1]))
+	digit := isDigit
+	if prefixed {
+		digit = isHexDigit
+	}
+
+	for i := 0; i < len(lit); i++ {
+		if lit[i] != '_' {
+			continue
+		}
+		if i == 0 || i+1 == len(lit) || !digit(rune(lit[i+1])) || !digit(rune(lit[i-1])) && !(prefixed && i == 2) {
+			return false
+		}
+	}
+	return true
+}
+
+// readString reads a string literal up to its closing quote, which is left as
+// the current char. Escape sequences are decoded in "..." strings, while
+// `...` raw strings are taken verbatim apart from carriage returns. Strings
+// may span lines; unterminated ones and bad escapes give an ILLEGAL token.
+func (l *Lexer) readString() *token.Token {
+	quote, p := l.ch, l.position
+	var b strings.Builder
+
 	for {
 		l.readChar()
-		if l.ch == '"' || l.ch == 0 {
-			break
+
+		switch {
+		case l.ch == 0 && l.position >= len(l.input):
+			l.errMsg = "unterminated string literal"
+			return token.NewToken(token.ILLEGAL, l.input[p:l.position])
+
+		case l.ch == quote:
+			if l.errMsg != "" {
+				return token.NewToken(token.ILLEGAL, l.input[p:l.position+1])
+			}
+			return token.NewToken(token.STRING, b.String())
+
+		case l.ch == '\\' && quote == '"':
+			l.readEscape(&b)
+
+		case l.ch == '\r' && quote == '`':
+			// Dropped so that raw strings do not depend on line endings.
+
+		default:
+			b.WriteRune(l.ch)
 		}
 	}
-	return l.input[p:l.position]
 }
 
-func isLetter(ch byte) bool {
-	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch == '_'
+// readEscape decodes the escape sequence starting at the current backslash,
+// leaving its last char as the current one.
+func (l *Lexer) readEscape(b *strings.Builder) {
+	l.readChar()
+
+	switch l.ch {
+	case 'n':
+		b.WriteByte('\n')
+	case 't':
+		b.WriteByte('\t')
+	case 'r':
+		b.WriteByte('\r')
+	case '0':
+		b.WriteByte(0)
+	case '\\', '"', '\'':
+		b.WriteRune(l.ch)
+	case 'x':
+		if v, ok := l.readHex(2); ok {
+			b.WriteRune(rune(v))
+		} else if l.errMsg == "" {
+			l.errMsg = "invalid escape sequence: \\x must be followed by 2 hex digits"
+		}
+	case 'u':
+		if v, ok := l.readHex(4); ok && utf8.ValidRune(rune(v)) {
+			b.WriteRune(rune(v))
+		} else if l.errMsg == "" {
+			l.errMsg = "invalid escape sequence: \\u must be followed by 4 hex digits of a valid code point"
+		}
+	default:
+		if l.errMsg == "" {
+			l.errMsg = fmt.Sprintf("unknown escape sequence '\\%c'", l.ch)
+		}
+	}
+}
+
+// readHex reads the n hex digits following the current char. On failure
+// the current char is the last valid one so that reading can go on.
+func (l *Lexer) readHex(n int) (uint64, bool) {
+	p := l.readPosition
+	for i := 0; i < n; i++ {
+		if !isHexDigit(l.peekChar()) {
+			return 0, false
+		}
+		l.readChar()
+	}
+
+	v, err := strconv.ParseUint(l.input[p:l.readPosition], 16, 32)
+	return v, err == nil
+}
+
+func isLetter(ch rune) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch == '_' || ch >= utf8.RuneSelf && unicode.IsLetter(ch)
+}
+
+func isHexDigit(ch rune) bool {
+	return isDigit(ch) || ch >= 'a' && ch <= 'f' || ch >= 'A' && ch <= 'F'
 }
 
-func isDigit(ch byte) bool {
+func isDigit(ch rune) bool {
 	return ch >= '0' && ch <= '9'
 }
@@ -15,7 +15,7 @@ let add = fn(x, y) {
 };
 
 let result = add(five, ten);
-!-/*5;
+!-/ *5;
 5 < 10 > 5;
 
 if (5 < 10) {
@@ -133,3 +133,284 @@ if (5 < 10) {
 		}
 	}
 }
+
+func TestCompoundAssignmentTokens(t *testing.T) {
+	input := `+= -= *= /= %= &= |= ^= <<= >>= << >> && || & |`
+
+	tests := []token.TokenType{
+		token.PLUS_ASSIGN, token.MINUS_ASSIGN, token.ASTERISK_ASSIGN, token.SLASH_ASSIGN,
+		token.MOD_ASSIGN, token.BITWISE_AND_ASSIGN, token.BITWISE_OR_ASSIGN, token.BITWISE_XOR_ASSIGN,
+		token.SHL_ASSIGN, token.SHR_ASSIGN, token.SHL, token.SHR,
+		token.LOGICAL_AND, token.LOGICAL_OR, token.BITWISE_AND, token.BITWISE_OR, token.EOF,
+	}
+
+	l := NewLexer(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt, tok.Type)
+		}
+
+		if tt != token.EOF && tok.Literal != string(tt) {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt, tok.Literal)
+		}
+	}
+}
+
+func TestNumberLiterals(t *testing.T) {
+	input := `1 1.5 2e10 3.0e-2 4E+1 5.e 6e 7e+`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.INT, "1"},
+		{token.FLOAT, "1.5"},
+		{token.FLOAT, "2e10"},
+		{token.FLOAT, "3.0e-2"},
+		{token.FLOAT, "4E+1"},
+		{token.INT, "5"},
+		{token.ILLEGAL, "."},
+		{token.IDENT, "e"},
+		{token.ILLEGAL, "6e"},
+		{token.ILLEGAL, "7e+"},
+		{token.EOF, ""},
+	}
+
+	l := NewLexer(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestPrefixedNumberLiterals(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{"0xFF", token.INT, "0xFF"},
+		{"0Xff_ff", token.INT, "0Xff_ff"},
+		{"0o755", token.INT, "0o755"},
+		{"0b1010", token.INT, "0b1010"},
+		{"0b_1010", token.INT, "0b_1010"},
+		{"1_000_000", token.INT, "1_000_000"},
+		{"1_000.000_1", token.FLOAT, "1_000.000_1"},
+		{"0x", token.ILLEGAL, "0x"},
+		{"0b102", token.ILLEGAL, "0b102"},
+		{"0o8", token.ILLEGAL, "0o8"},
+		{"0xFG", token.ILLEGAL, "0xFG"},
+		{"1__000", token.ILLEGAL, "1__000"},
+		{"1000_", token.ILLEGAL, "1000_"},
+		{"1_e5", token.ILLEGAL, "1_e5"},
+	}
+
+	for i, tt := range tests {
+		tok := NewLexer(tt.input).NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestStringLiterals(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{`"a\nb\tc"`, token.STRING, "a\nb\tc"},
+		{`"\"quoted\" \\ \'"`, token.STRING, `"quoted" \ '`},
+		{`"\x41\u00e9\u4e16"`, token.STRING, "Aé世"},
+		{`"\xff\xE9"`, token.STRING, "ÿé"},
+		{`"naïve 世界"`, token.STRING, "naïve 世界"},
+		{"\"multi\nline\"", token.STRING, "multi\nline"},
+		{"`raw \\n \"`", token.STRING, `raw \n "`},
+		{"`a\r\nb`", token.STRING, "a\nb"},
+		{`"abc`, token.ILLEGAL, `"abc`},
+		{"`abc", token.ILLEGAL, "`abc"},
+		{`"a\qb"`, token.ILLEGAL, `"a\qb"`},
+		{`"\x4"`, token.ILLEGAL, `"\x4"`},
+		{`"\ud800"`, token.ILLEGAL, `"\ud800"`},
+	}
+
+	for i, tt := range tests {
+		tok := NewLexer(tt.input).NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestIllegalTokenErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`x = "abc`, "1:5: unterminated string literal"},
+		{`"a\qb"`, `1:1: unknown escape sequence '\q'`},
+		{`"\xZZ"`, `1:1: invalid escape sequence: \x must be followed by 2 hex digits`},
+		{"a @ b", `1:3: illegal character "@"`},
+		{"a\n/* x /* y */ z", "2:1: unterminated comment"},
+		{"0x", "1:1: hexadecimal literal has no digits"},
+		{"1 + 0b102", "1:5: invalid digit '2' in binary literal"},
+		{"1__0", "1:1: '_' must separate successive digits"},
+		{"print(1e)", "1:7: exponent has no digits"},
+		{"2.5E-x", "1:1: exponent has no digits"},
+		{"f(...a.b)", `1:7: illegal character "."`},
+	}
+
+	for i, tt := range tests {
+		l := NewLexer(tt.input)
+		for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		}
+
+		errors := l.Errors()
+		if len(errors) != 1 {
+			t.Fatalf("tests[%d] - expected 1 error, got=%d", i, len(errors))
+		}
+
+		if errors[0].Error() != tt.expected {
+			t.Errorf("tests[%d] - wrong error. expected=%q, got=%q", i, tt.expected, errors[0].Error())
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `// leading
+let a = 1; // trailing
+/* block /* nested */ still comment */ a /* inline */ / 2 //`
+
+	tests := []struct {
+		expectedType     token.TokenType
+		expectedLiteral  string
+		expectedComments []string
+	}{
+		{token.LET, "let", []string{"// leading"}},
+		{token.IDENT, "a", nil},
+		{token.ASSIGN, "=", nil},
+		{token.INT, "1", nil},
+		{token.SEMICOLON, ";", nil},
+		{token.IDENT, "a", []string{"// trailing", "/* block /* nested */ still comment */"}},
+		{token.SLASH, "/", []string{"/* inline */"}},
+		{token.INT, "2", nil},
+		{token.EOF, "", []string{"//"}},
+	}
+
+	l := NewLexer(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - token wrong. expected=%q %q, got=%q %q", i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+
+		if len(tok.Comments) != len(tt.expectedComments) {
+			t.Fatalf("tests[%d] - wrong number of comments. expected=%d, got=%d", i, len(tt.expectedComments), len(tok.Comments))
+		}
+
+		for j, c := range tok.Comments {
+			if c.Text != tt.expectedComments[j] {
+				t.Errorf("tests[%d] - comment wrong. expected=%q, got=%q", i, tt.expectedComments[j], c.Text)
+			}
+		}
+	}
+
+	if len(l.Errors()) != 0 {
+		t.Errorf("unexpected errors: %v", l.Errors())
+	}
+}
+
+func TestUnicodeIdentifiers(t *testing.T) {
+	input := "let café = π2 + 名前;"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedColumn  int
+	}{
+		{token.LET, "let", 1},
+		{token.IDENT, "café", 5},
+		{token.ASSIGN, "=", 10},
+		{token.IDENT, "π2", 12},
+		{token.PLUS, "+", 15},
+		{token.IDENT, "名前", 17},
+		{token.SEMICOLON, ";", 19},
+	}
+
+	l := NewLexer(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - token wrong. expected=%q %q, got=%q %q", i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+
+		if tok.Pos.Column != tt.expectedColumn {
+			t.Errorf("tests[%d] - column wrong. expected=%d, got=%d", i, tt.expectedColumn, tok.Pos.Column)
+		}
+	}
+}
+
+func TestTokenPosition(t *testing.T) {
+	input := `let x = 5;
+  x + "ab"
+`
+
+	tests := []struct {
+		expectedLiteral string
+		expectedPos     token.Position
+		expectedEnd     token.Position
+	}{
+		{"let", token.Position{Filename: "a.yl", Offset: 0, Line: 1, Column: 1}, token.Position{Filename: "a.yl", Offset: 3, Line: 1, Column: 4}},
+		{"x", token.Position{Filename: "a.yl", Offset: 4, Line: 1, Column: 5}, token.Position{Filename: "a.yl", Offset: 5, Line: 1, Column: 6}},
+		{"=", token.Position{Filename: "a.yl", Offset: 6, Line: 1, Column: 7}, token.Position{Filename: "a.yl", Offset: 7, Line: 1, Column: 8}},
+		{"5", token.Position{Filename: "a.yl", Offset: 8, Line: 1, Column: 9}, token.Position{Filename: "a.yl", Offset: 9, Line: 1, Column: 10}},
+		{";", token.Position{Filename: "a.yl", Offset: 9, Line: 1, Column: 10}, token.Position{Filename: "a.yl", Offset: 10, Line: 1, Column: 11}},
+		{"x", token.Position{Filename: "a.yl", Offset: 13, Line: 2, Column: 3}, token.Position{Filename: "a.yl", Offset: 14, Line: 2, Column: 4}},
+		{"+", token.Position{Filename: "a.yl", Offset: 15, Line: 2, Column: 5}, token.Position{Filename: "a.yl", Offset: 16, Line: 2, Column: 6}},
+		{"ab", token.Position{Filename: "a.yl", Offset: 17, Line: 2, Column: 7}, token.Position{Filename: "a.yl", Offset: 21, Line: 2, Column: 11}},
+		{"", token.Position{Filename: "a.yl", Offset: 22, Line: 3, Column: 1}, token.Position{Filename: "a.yl", Offset: 22, Line: 3, Column: 1}},
+	}
+
+	l := NewFileLexer("a.yl", input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+
+		if tok.Pos != tt.expectedPos {
+			t.Fatalf("tests[%d] - pos wrong. expected=%+v, got=%+v", i, tt.expectedPos, tok.Pos)
+		}
+
+		if tok.End != tt.expectedEnd {
+			t.Fatalf("tests[%d] - end wrong. expected=%+v, got=%+v", i, tt.expectedEnd, tok.End)
+		}
+	}
+}
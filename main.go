@@ -1,12 +1,80 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"go-interpreter/object"
 	"go-interpreter/repl"
+	"go-interpreter/runner"
+	"io/fs"
 	"os"
+	"strings"
 )
 
+const usage = `usage:
+  yl                        start the interactive REPL
+  yl run <file> [args...]   run a script
+  yl <file> [args...]       run a script (e.g. from a '#!/usr/bin/env yl' line)
+  yl -e <code> [args...]    evaluate code and print its value
+`
+
 func main() {
-	fmt.Println("Welcome to YL")
-	repl.Start(os.Stdin, os.Stdout)
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 {
+		fmt.Println("Welcome to YL")
+		repl.Start(os.Stdin, os.Stdout)
+		return 0
+	}
+
+	switch args[0] {
+	case "-h", "--help", "help":
+		fmt.Print(usage)
+		return 0
+
+	case "-e":
+		if len(args) < 2 {
+			return usageError("-e requires an argument")
+		}
+
+		res, err := runner.Run("<eval>", args[1], runner.NewEnvironment(args[2:]), os.Stderr)
+		if err != nil {
+			return 1
+		}
+		if res != nil && res != object.NULL && res.Type() != object.EXPLIST_OBJ {
+			fmt.Println(res.Inspect())
+		}
+		return 0
+
+	case "run":
+		if len(args) < 2 {
+			return usageError("run requires a file")
+		}
+		return runFile(args[1], args[2:])
+
+	default:
+		if strings.HasPrefix(args[0], "-") {
+			return usageError(fmt.Sprintf("unknown flag '%s'", args[0]))
+		}
+		return runFile(args[0], args[1:])
+	}
+}
+
+func runFile(path string, args []string) int {
+	if _, err := runner.RunFile(path, runner.NewEnvironment(args), os.Stderr); err != nil {
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			fmt.Fprintln(os.Stderr, "yl:", err)
+		}
+		return 1
+	}
+	return 0
+}
+
+func usageError(msg string) int {
+	fmt.Fprintln(os.Stderr, "yl:", msg)
+	fmt.Fprint(os.Stderr, usage)
+	return 2
 }
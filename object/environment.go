@@ -1,5 +1,7 @@
 package object
 
+import "sort"
+
 type Environment struct {
 	store map[string]Object
 	outer *Environment
@@ -30,3 +32,30 @@ func (e *Environment) IsExist(name string) bool {
 func (e *Environment) Set(name string, val Object) {
 	e.store[name] = val
 }
+
+// AllNames returns the names visible from this scope, including the ones of
+// enclosing scopes, sorted and without duplicates.
+func (e *Environment) AllNames() []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for env := e; env != nil; env = env.outer {
+		for name := range env.store {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Names returns the names bound in this scope, sorted.
+func (e *Environment) Names() []string {
+	names := make([]string, 0, len(e.store))
+	for name := range e.store {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -1,22 +1,27 @@
 package object
 
 import (
+	"errors"
 	"fmt"
 	"go-interpreter/ast"
+	"math"
+	"math/big"
 	"strconv"
 	"strings"
 )
 
 const (
-	INTEGER_OBJ      = "INTEGER"
-	BOOLEAN_OBJ      = "BOOLEAN"
-	STRING_OBJ       = "STRING"
-	RETURN_VALUE_OBJ = "RETURN_VALUE"
-	FUNCTION_OBJ     = "FUNCTION"
-	BUILTIN_OBJ      = "BUILTIN"
-	ARRAY_OBJ        = "ARRAY"
-	EXPLIST_OBJ      = "EXPLIST"
-	NULL_OBJ         = "NULL"
+	INTEGER_OBJ  = "INTEGER"
+	FLOAT_OBJ    = "FLOAT"
+	BOOLEAN_OBJ  = "BOOLEAN"
+	STRING_OBJ   = "STRING"
+	FUNCTION_OBJ = "FUNCTION"
+	BUILTIN_OBJ  = "BUILTIN"
+	ARRAY_OBJ    = "ARRAY"
+	HASH_OBJ     = "HASH"
+	RANGE_OBJ    = "RANGE"
+	EXPLIST_OBJ  = "EXPLIST"
+	NULL_OBJ     = "NULL"
 )
 
 var (
@@ -32,11 +37,72 @@ type Object interface {
 	Inspect() string
 }
 
+// HashKey identifies a hash key by value, so equal keys map to the same pair.
+type HashKey struct {
+	Type  ObjectType
+	Value string
+}
+
+type Hashable interface {
+	Object
+	HashKey() HashKey
+}
+
 type Integer struct{ Value int64 }
 
 func NewInteger(v int64) *Integer   { return &Integer{Value: v} }
 func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
 func (i *Integer) Inspect() string  { return strconv.FormatInt(i.Value, 10) }
+func (i *Integer) HashKey() HashKey { return HashKey{i.Type(), i.Inspect()} }
+
+// BigInteger holds the integers that do not fit in an int64. It reports the
+// same type as Integer, so scripts never see the difference.
+type BigInteger struct{ Value *big.Int }
+
+// IntegerFromBig returns v as an Integer when it fits in an int64, otherwise
+// as a BigInteger. v must not be modified afterwards.
+func IntegerFromBig(v *big.Int) Object {
+	if v.IsInt64() {
+		return NewInteger(v.Int64())
+	}
+	return &BigInteger{Value: v}
+}
+
+func (bi *BigInteger) Type() ObjectType { return INTEGER_OBJ }
+func (bi *BigInteger) Inspect() string  { return bi.Value.String() }
+func (bi *BigInteger) HashKey() HashKey { return HashKey{bi.Type(), bi.Inspect()} }
+
+type Float struct{ Value float64 }
+
+func NewFloat(v float64) *Float   { return &Float{Value: v} }
+func (f *Float) Type() ObjectType { return FLOAT_OBJ }
+
+// Inspect always marks a float as such, printing 2.0 rather than 2.
+func (f *Float) Inspect() string {
+	switch {
+	case math.IsInf(f.Value, 1):
+		return "inf"
+	case math.IsInf(f.Value, -1):
+		return "-inf"
+	case math.IsNaN(f.Value):
+		return "nan"
+	}
+
+	s := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
+
+// HashKey makes an integral float the same key as the equal integer.
+func (f *Float) HashKey() HashKey {
+	if f.Value == math.Trunc(f.Value) && !math.IsInf(f.Value, 0) {
+		v, _ := big.NewFloat(f.Value).Int(nil)
+		return IntegerFromBig(v).(Hashable).HashKey()
+	}
+	return HashKey{f.Type(), strconv.FormatFloat(f.Value, 'g', -1, 64)}
+}
 
 type Boolean struct{ Value bool }
 
@@ -49,44 +115,46 @@ func NewBoolean(v bool) *Boolean {
 
 func (b *Boolean) Type() ObjectType { return BOOLEAN_OBJ }
 func (b *Boolean) Inspect() string  { return strconv.FormatBool(b.Value) }
+func (b *Boolean) HashKey() HashKey { return HashKey{b.Type(), b.Inspect()} }
 
 type String struct{ Value string }
 
 func NewString(v string) *String   { return &String{Value: v} }
 func (s *String) Type() ObjectType { return STRING_OBJ }
 func (s *String) Inspect() string  { return fmt.Sprintf("%q", s.Value) }
+func (s *String) HashKey() HashKey { return HashKey{s.Type(), s.Value} }
 
 type Null struct{}
 
 func (n *Null) Type() ObjectType { return NULL_OBJ }
 func (n *Null) Inspect() string  { return "null" }
 
-type ReturnValue struct{ Value Object }
-
-func NewReturnValue(v Object) *ReturnValue { return &ReturnValue{Value: v} }
-func (rv *ReturnValue) Type() ObjectType   { return RETURN_VALUE_OBJ }
-func (rv *ReturnValue) Inspect() string    { return rv.Value.Inspect() }
-
 type Function struct {
+	Name       string // empty for anonymous functions
 	Parameters []*ast.Identifier
+	Defaults   []ast.Expression // the default value of each parameter, nil if none
+	Rest       *ast.Identifier
 	Body       *ast.BlockStatement
 	Env        *Environment
 }
 
-func NewFunction(params []*ast.Identifier, body *ast.BlockStatement, env *Environment) *Function {
-	return &Function{Parameters: params, Body: body, Env: env}
+func NewFunction(fl *ast.FunctionLiteral, env *Environment) *Function {
+	fn := &Function{Parameters: fl.Params, Defaults: fl.Defaults, Rest: fl.Rest, Body: fl.Body, Env: env}
+	if fl.Name != nil {
+		fn.Name = fl.Name.Value
+	}
+	return fn
 }
 
 func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
 func (f *Function) Inspect() string {
 	var b strings.Builder
-	params := []string{}
-	for _, p := range f.Parameters {
-		params = append(params, p.String())
-	}
 	b.WriteString("fn")
+	if f.Name != "" {
+		b.WriteString(" " + f.Name)
+	}
 	b.WriteString("(")
-	b.WriteString(strings.Join(params, ", "))
+	b.WriteString(ast.FormatParams(f.Parameters, f.Defaults, f.Rest))
 	b.WriteString(") {\n")
 	b.WriteString(f.Body.String())
 	b.WriteString("\n}")
@@ -129,7 +197,102 @@ func (arr *Array) Inspect() string {
 	return b.String()
 }
 
-type Assignable interface{ Set(Object) }
+// Range is the arithmetic progression from Start up to, but excluding, Stop
+// by Step, computed as it is iterated rather than stored.
+type Range struct{ Start, Stop, Step int64 }
+
+func NewRange(start, stop, step int64) *Range { return &Range{start, stop, step} }
+func (r *Range) Type() ObjectType             { return RANGE_OBJ }
+func (r *Range) Inspect() string {
+	if r.Step == 1 {
+		return fmt.Sprintf("range(%d, %d)", r.Start, r.Stop)
+	}
+	return fmt.Sprintf("range(%d, %d, %d)", r.Start, r.Stop, r.Step)
+}
+
+// Len returns the number of values in the range, which may not fit in an
+// int64 for a range over most of it.
+func (r *Range) Len() *big.Int {
+	var n *big.Int
+	if r.Step > 0 && r.Start < r.Stop {
+		n = big.NewInt(r.Stop)
+		n.Sub(n, big.NewInt(r.Start))
+	} else if r.Step < 0 && r.Start > r.Stop {
+		n = big.NewInt(r.Start)
+		n.Sub(n, big.NewInt(r.Stop))
+	} else {
+		return new(big.Int)
+	}
+
+	// ceil(n / |step|)
+	step := big.NewInt(r.Step)
+	step.Abs(step)
+	n.Add(n, step)
+	n.Sub(n, big.NewInt(1))
+	return n.Quo(n, step)
+}
+
+type HashPair struct{ Key, Value Object }
+
+// Hash maps hashable keys to values, keeping the keys in insertion order.
+type Hash struct {
+	Pairs map[HashKey]*HashPair
+	Keys  []HashKey
+}
+
+func NewHash() *Hash             { return &Hash{Pairs: map[HashKey]*HashPair{}} }
+func (h *Hash) Type() ObjectType { return HASH_OBJ }
+func (h *Hash) Inspect() string {
+	var b strings.Builder
+	pairs := []string{}
+	for _, k := range h.Keys {
+		p := h.Pairs[k]
+		pairs = append(pairs, p.Key.Inspect()+": "+p.Value.Inspect())
+	}
+	b.WriteString("{")
+	b.WriteString(strings.Join(pairs, ", "))
+	b.WriteString("}")
+	return b.String()
+}
+
+func (h *Hash) Get(key Hashable) (Object, bool) {
+	if p, ok := h.Pairs[key.HashKey()]; ok {
+		return p.Value, true
+	}
+	return nil, false
+}
+
+func (h *Hash) Set(key Hashable, val Object) {
+	k := key.HashKey()
+	if p, ok := h.Pairs[k]; ok {
+		p.Value = val
+		return
+	}
+	h.Pairs[k] = &HashPair{Key: key, Value: val}
+	h.Keys = append(h.Keys, k)
+}
+
+// Delete removes key and returns its value, if it was present.
+func (h *Hash) Delete(key Hashable) (Object, bool) {
+	k := key.HashKey()
+	p, ok := h.Pairs[k]
+	if !ok {
+		return nil, false
+	}
+	delete(h.Pairs, k)
+	for i, hk := range h.Keys {
+		if hk == k {
+			h.Keys = append(h.Keys[:i], h.Keys[i+1:]...)
+			break
+		}
+	}
+	return p.Value, true
+}
+
+type Assignable interface {
+	Get() Object
+	Set(Object) error
+}
 
 type Identifier struct {
 	Name string
@@ -137,14 +300,111 @@ type Identifier struct {
 }
 
 func NewIdentifier(name string, env *Environment) *Identifier { return &Identifier{name, env} }
-func (i *Identifier) Set(obj Object)                          { i.Env.Set(i.Name, obj) }
+func (i *Identifier) Set(obj Object) error {
+	i.Env.Set(i.Name, obj)
+	return nil
+}
+func (i *Identifier) Get() Object {
+	v, _ := i.Env.Get(i.Name)
+	return v
+}
 
+// ArrayIndex is the element Arr[Idx]. It refers to the array rather than its
+// elements so that it sees the array grow or shrink before it is set.
 type ArrayIndex struct {
-	Arr []Object
+	Arr *Array
 	Idx int64
 }
 
-func NewArrayIndex(arr []Object, idx int64) *ArrayIndex { return &ArrayIndex{arr, idx} }
-func (ai *ArrayIndex) Set(obj Object)                   { ai.Arr[ai.Idx] = obj }
-func (ai *ArrayIndex) Type() ObjectType                 { return "" }
-func (ai *ArrayIndex) Inspect() string                  { return "" }
+func NewArrayIndex(arr *Array, idx int64) *ArrayIndex { return &ArrayIndex{arr, idx} }
+func (ai *ArrayIndex) Get() Object                    { return ai.Arr.Elements[ai.Idx] }
+func (ai *ArrayIndex) Type() ObjectType               { return "" }
+func (ai *ArrayIndex) Inspect() string                { return "" }
+func (ai *ArrayIndex) Set(obj Object) error {
+	if ai.Idx >= int64(len(ai.Arr.Elements)) {
+		return errors.New("array index out of range")
+	}
+	ai.Arr.Elements[ai.Idx] = obj
+	return nil
+}
+
+// ArraySlice is the slice Arr[Start:Stop:Step], with bounds already resolved
+// against the length of Arr as in Python.
+type ArraySlice struct {
+	Arr               *Array
+	Start, Stop, Step int
+}
+
+func NewArraySlice(arr *Array, start, stop, step int) *ArraySlice {
+	return &ArraySlice{arr, start, stop, step}
+}
+func (as *ArraySlice) Type() ObjectType { return "" }
+func (as *ArraySlice) Inspect() string  { return "" }
+
+// Len returns the number of elements in the slice.
+func (as *ArraySlice) Len() int {
+	if as.Step > 0 && as.Start < as.Stop {
+		return (as.Stop - as.Start + as.Step - 1) / as.Step
+	}
+	if as.Step < 0 && as.Start > as.Stop {
+		return (as.Start - as.Stop - as.Step - 1) / -as.Step
+	}
+	return 0
+}
+
+func (as *ArraySlice) Get() Object {
+	elements := make([]Object, as.Len())
+	for i := range elements {
+		elements[i] = as.Arr.Elements[as.Start+i*as.Step]
+	}
+	return NewArray(elements)
+}
+
+// Set replaces the elements of the slice with those of obj, which must be an
+// array. A slice with a step of 1 may change length, others may not.
+func (as *ArraySlice) Set(obj Object) error {
+	arr, ok := obj.(*Array)
+	if !ok {
+		return fmt.Errorf("can only assign an array to a slice, not '%s'", obj.Type())
+	}
+
+	if as.Step == 1 {
+		stop := as.Stop
+		if stop < as.Start {
+			stop = as.Start
+		}
+		elements := append([]Object{}, as.Arr.Elements[:as.Start]...)
+		elements = append(elements, arr.Elements...)
+		as.Arr.Elements = append(elements, as.Arr.Elements[stop:]...)
+		return nil
+	}
+
+	if len(arr.Elements) != as.Len() {
+		return fmt.Errorf("cannot assign %d elements to a slice of %d elements with step %d",
+			len(arr.Elements), as.Len(), as.Step)
+	}
+	// Copy first, in case arr is the array being assigned to.
+	for i, e := range append([]Object{}, arr.Elements...) {
+		as.Arr.Elements[as.Start+i*as.Step] = e
+	}
+	return nil
+}
+
+type HashIndex struct {
+	Hash *Hash
+	Key  Hashable
+}
+
+func NewHashIndex(hash *Hash, key Hashable) *HashIndex { return &HashIndex{hash, key} }
+func (hi *HashIndex) Set(obj Object) error {
+	hi.Hash.Set(hi.Key, obj)
+	return nil
+}
+func (hi *HashIndex) Get() Object {
+	if v, ok := hi.Hash.Get(hi.Key); ok {
+		return v
+	}
+	return NULL
+}
+func (hi *HashIndex) Type() ObjectType { return "" }
+func (hi *HashIndex) Inspect() string  { return "" }
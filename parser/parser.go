@@ -1,10 +1,12 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"go-interpreter/ast"
 	"go-interpreter/lexer"
 	"go-interpreter/token"
+	"math/big"
 	"strconv"
 )
 
@@ -23,34 +25,60 @@ const (
 	SUM         // + -
 	PRODUCT     // * / %
 	PREFIX      // - ! ~ ++ --
+	POSTFIX     // ++ --
 	CALL        // ()
 	INDEX       // []
 )
 
 var precedences = map[token.TokenType]int{
-	token.COMMA:       ASSIGN,
-	token.ASSIGN:      ASSIGN,
-	token.PLUS_ASSIGN: ASSIGN,
-	token.LOGICAL_AND: LOGICAL_AND,
-	token.LOGICAL_OR:  LOGICAL_OR,
-	token.BITWISE_AND: BITWISE_AND,
-	token.BITWISE_OR:  BITWISE_OR,
-	token.BITWISE_XOR: BITWISE_NOR,
-	token.EQ:          EQUALS,
-	token.NOT_EQ:      EQUALS,
-	token.LT:          LESSGREATER,
-	token.GT:          LESSGREATER,
-	token.LE:          LESSGREATER,
-	token.GE:          LESSGREATER,
-	token.SHL:         SHIFT,
-	token.SHR:         SHIFT,
-	token.PLUS:        SUM,
-	token.MINUS:       SUM,
-	token.SLASH:       PRODUCT,
-	token.ASTERISK:    PRODUCT,
-	token.MOD:         PRODUCT,
-	token.LPAREN:      CALL,
-	token.LBRACKET:    INDEX,
+	token.COMMA:              ASSIGN,
+	token.ASSIGN:             ASSIGN,
+	token.PLUS_ASSIGN:        ASSIGN,
+	token.MINUS_ASSIGN:       ASSIGN,
+	token.ASTERISK_ASSIGN:    ASSIGN,
+	token.SLASH_ASSIGN:       ASSIGN,
+	token.MOD_ASSIGN:         ASSIGN,
+	token.BITWISE_AND_ASSIGN: ASSIGN,
+	token.BITWISE_OR_ASSIGN:  ASSIGN,
+	token.BITWISE_XOR_ASSIGN: ASSIGN,
+	token.SHL_ASSIGN:         ASSIGN,
+	token.SHR_ASSIGN:         ASSIGN,
+	token.LOGICAL_AND:        LOGICAL_AND,
+	token.LOGICAL_OR:         LOGICAL_OR,
+	token.BITWISE_AND:        BITWISE_AND,
+	token.BITWISE_OR:         BITWISE_OR,
+	token.BITWISE_XOR:        BITWISE_NOR,
+	token.EQ:                 EQUALS,
+	token.NOT_EQ:             EQUALS,
+	token.LT:                 LESSGREATER,
+	token.GT:                 LESSGREATER,
+	token.LE:                 LESSGREATER,
+	token.GE:                 LESSGREATER,
+	token.SHL:                SHIFT,
+	token.SHR:                SHIFT,
+	token.PLUS:               SUM,
+	token.MINUS:              SUM,
+	token.SLASH:              PRODUCT,
+	token.ASTERISK:           PRODUCT,
+	token.MOD:                PRODUCT,
+	token.INC:                POSTFIX,
+	token.DEC:                POSTFIX,
+	token.LPAREN:             CALL,
+	token.LBRACKET:           INDEX,
+}
+
+// compoundOperators maps each compound assignment to its binary operator.
+var compoundOperators = map[token.TokenType]string{
+	token.PLUS_ASSIGN:        token.PLUS,
+	token.MINUS_ASSIGN:       token.MINUS,
+	token.ASTERISK_ASSIGN:    token.ASTERISK,
+	token.SLASH_ASSIGN:       token.SLASH,
+	token.MOD_ASSIGN:         token.MOD,
+	token.BITWISE_AND_ASSIGN: token.BITWISE_AND,
+	token.BITWISE_OR_ASSIGN:  token.BITWISE_OR,
+	token.BITWISE_XOR_ASSIGN: token.BITWISE_XOR,
+	token.SHL_ASSIGN:         token.SHL,
+	token.SHR_ASSIGN:         token.SHR,
 }
 
 type (
@@ -62,6 +90,8 @@ type Parser struct {
 	l                   *lexer.Lexer
 	curToken, peekToken *token.Token
 	errors              []error
+	parens, braces      int      // nesting depth at curToken, used for error recovery
+	loops               []string // labels of the enclosing loops, "" if unlabeled
 	prefixParseFns      map[token.TokenType]prefixParseFn
 	infixParseFns       map[token.TokenType]infixParseFn
 }
@@ -76,6 +106,7 @@ func NewParser(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
 	p.registerPrefix(token.INC, p.parsePrefixIncAndDec)
@@ -84,8 +115,11 @@ func NewParser(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.FALSE, p.parseBoolean)
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(token.IF, p.parseIfExpression)
+	p.registerPrefix(token.SWITCH, p.parseSwitchExpression)
+	p.registerPrefix(token.MATCH, p.parseMatchExpression)
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
 	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
+	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
 	p.registerPrefix(token.BITWISE_NOT, p.parsePrefixExpression)
 
 	p.registerInfix(token.PLUS, p.parseInfixExpression)
@@ -110,7 +144,11 @@ func NewParser(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.LE, p.parseInfixExpression)
 	p.registerInfix(token.GE, p.parseInfixExpression)
 	p.registerInfix(token.COMMA, p.parseCommaExpression)
-	p.registerInfix(token.PLUS_ASSIGN, p.parseAssignmentConverter)
+	p.registerInfix(token.INC, p.parsePostfixIncAndDec)
+	p.registerInfix(token.DEC, p.parsePostfixIncAndDec)
+	for tp := range compoundOperators {
+		p.registerInfix(tp, p.parseAssignmentConverter)
+	}
 
 	p.nextToken()
 	p.nextToken()
@@ -121,30 +159,222 @@ func NewParser(l *lexer.Lexer) *Parser {
 func (p *Parser) ParseProgram() *ast.Program {
 	program := &ast.Program{}
 
-	for ; !p.curTokenIs(token.EOF); p.nextToken() {
-		if stmt, err := p.parseStatement(); err != nil {
-			p.errors = append(p.errors, err)
-		} else {
-			program.Stmts = append(program.Stmts, stmt)
-		}
+	for !p.curTokenIs(token.EOF) {
+		program.Stmts = append(program.Stmts, p.parseStatementWithRecovery())
 	}
 
 	return program
 }
 
+// parseStatementWithRecovery parses the statement at the current token and
+// moves on to the next one. On a syntax error it records the error, skips to
+// the next synchronization point and returns a BadStatement covering the
+// skipped tokens, so one mistake does not cascade into bogus follow-up errors.
+func (p *Parser) parseStatementWithRecovery() ast.Statement {
+	start, parens, braces := p.curToken, p.parens, p.braces
+
+	stmt, err := p.parseStatement()
+	if err == nil {
+		p.nextToken()
+		return stmt
+	}
+
+	p.errors = append(p.errors, err)
+	to := p.synchronize(start, parens, braces)
+	p.parens = parens
+	return &ast.BadStatement{Token: start, To: to}
+}
+
+// synchronize skips tokens until the start of the next statement at the
+// nesting depth the failed statement began at: just past a ';', at the '}'
+// closing the enclosing block, or at a statement keyword. It always moves past
+// start and returns the end of the last skipped token.
+func (p *Parser) synchronize(start *token.Token, parens, braces int) token.Position {
+	end := start.End
+	for !p.curTokenIs(token.EOF) {
+		if p.curToken != start {
+			switch p.curToken.Type {
+			case token.RBRACE:
+				if p.braces < braces {
+					return end
+				}
+			case token.LET, token.RETURN, token.FORLOOP, token.WHILE, token.DO, token.BREAK, token.CONTINUE,
+				token.CASE, token.DEFAULT:
+				if p.braces <= braces {
+					return end
+				}
+			case token.FUNCTION:
+				if p.braces <= braces && p.parens <= parens {
+					return end
+				}
+			}
+		}
+
+		end = p.curToken.End
+		if p.curTokenIs(token.SEMICOLON) && p.braces <= braces && p.parens <= parens {
+			p.nextToken()
+			return end
+		}
+		p.nextToken()
+	}
+	return end
+}
+
 func (p *Parser) parseStatement() (ast.Statement, error) {
 	switch p.curToken.Type {
 	case token.LET:
 		return p.parseLetStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
-	case token.FORLOOP:
-		return p.parseForLoopStatement()
+	case token.FORLOOP, token.WHILE, token.DO:
+		return p.parseLoopStatement(nil)
+	case token.BREAK, token.CONTINUE:
+		return p.parseBranchStatement()
+	case token.IDENT:
+		if p.peekTokenIs(token.COLON) {
+			label := p.newIdentifier()
+			p.nextToken()
+			return p.parseLabeledStatement(label)
+		}
+		return p.parseExpressionStatement()
+	case token.LBRACE:
+		return p.parseBraceStatement()
+	case token.FUNCTION:
+		if p.peekTokenIs(token.IDENT) {
+			return p.parseFunctionDeclaration()
+		}
+		return p.parseExpressionStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
 }
 
+// parseBraceStatement parses a statement starting with '{'. It is a hash
+// literal if it is empty or its first element is followed by ':', as in
+// '{"a": 1}["a"]', and a block otherwise. 'label:' only starts a block when
+// a loop follows it.
+func (p *Parser) parseBraceStatement() (ast.Statement, error) {
+	lbrace := p.curToken
+	if p.peekTokenIs(token.RBRACE) {
+		return p.parseExpressionStatement()
+	}
+	if _, ok := p.prefixParseFns[p.peekToken.Type]; !ok || p.peekTokenIs(token.LBRACE) {
+		return p.parseBlockStatement()
+	}
+
+	p.nextToken()
+	tok := p.curToken
+	var first ast.Statement
+	key, err := p.parseExpression(LOWEST)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		if label, ok := key.(*ast.Identifier); ok && isLoopKeyword(p.peekToken.Type) {
+			first, err = p.parseLabeledStatement(label)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			hash, err := p.parseHashPairs(&ast.HashLiteral{Token: lbrace}, key)
+			if err != nil {
+				return nil, err
+			}
+			return p.parseExpressionStatementFrom(lbrace, hash)
+		}
+	} else {
+		first = &ast.ExpressionStatement{Token: tok, Expr: key}
+		if p.peekTokenIs(token.SEMICOLON) {
+			p.nextToken()
+		}
+	}
+
+	p.nextToken()
+	return p.parseBlockRest(&ast.BlockStatement{Token: lbrace, Stmts: []ast.Statement{first}})
+}
+
+// parseLabeledStatement parses the loop after 'label:', the current token
+// being the ':'; only loops can be labeled.
+func (p *Parser) parseLabeledStatement(label *ast.Identifier) (ast.Statement, error) {
+	for _, l := range p.loops {
+		if l == label.Value {
+			return nil, p.newError(label.Token, "label '%s' already defined", label.Value)
+		}
+	}
+
+	if !isLoopKeyword(p.peekToken.Type) {
+		return nil, p.newError(p.peekToken, "label '%s' must be followed by a loop", label.Value)
+	}
+	p.nextToken()
+	return p.parseLoopStatement(label)
+}
+
+func isLoopKeyword(t token.TokenType) bool {
+	return t == token.FORLOOP || t == token.WHILE || t == token.DO
+}
+
+// parseLoopStatement parses the loop starting at the current 'for', 'while'
+// or 'do' token.
+func (p *Parser) parseLoopStatement(label *ast.Identifier) (ast.Statement, error) {
+	switch p.curToken.Type {
+	case token.WHILE:
+		return p.parseWhileStatement(label)
+	case token.DO:
+		return p.parseDoWhileStatement(label)
+	default:
+		return p.parseForStatement(label)
+	}
+}
+
+func (p *Parser) parseBranchStatement() (*ast.BranchStatement, error) {
+	stmt := &ast.BranchStatement{Token: p.curToken}
+
+	if p.peekTokenIs(token.IDENT) && p.peekToken.Pos.Line == p.curToken.End.Line {
+		p.nextToken()
+		stmt.Label = ast.NewIdentifier(p.curToken, p.curToken.Literal)
+	}
+
+	if err := p.checkBranch(stmt); err != nil {
+		return nil, err
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt, nil
+}
+
+// checkBranch reports a 'break' or 'continue' outside of the loop it names.
+func (p *Parser) checkBranch(stmt *ast.BranchStatement) error {
+	if stmt.Label == nil {
+		if len(p.loops) == 0 {
+			return p.newError(stmt.Token, "'%s' outside loop", stmt.TokenLiteral())
+		}
+		return nil
+	}
+
+	for _, l := range p.loops {
+		if l == stmt.Label.Value {
+			return nil
+		}
+	}
+	return token.NewError(stmt.Label.Pos(), stmt.Label.End(), fmt.Sprintf("undefined loop label '%s'", stmt.Label.Value))
+}
+
+// enterLoop records a loop with the given label being parsed until the
+// returned function is called.
+func (p *Parser) enterLoop(label *ast.Identifier) func() {
+	name := ""
+	if label != nil {
+		name = label.Value
+	}
+	p.loops = append(p.loops, name)
+	return func() { p.loops = p.loops[:len(p.loops)-1] }
+}
+
 func (p *Parser) parseLetStatement() (*ast.LetStatement, error) {
 	stmt := &ast.LetStatement{Token: p.curToken}
 	p.nextToken()
@@ -186,10 +416,21 @@ func (p *Parser) parseReturnStatement() (*ast.ReturnStatement, error) {
 }
 
 func (p *Parser) parseExpressionStatement() (*ast.ExpressionStatement, error) {
-	stmt := &ast.ExpressionStatement{Token: p.curToken}
+	tok := p.curToken
+	expr, err := p.parsePrefix()
+	if err != nil {
+		return nil, err
+	}
+	return p.parseExpressionStatementFrom(tok, expr)
+}
+
+// parseExpressionStatementFrom parses the rest of an expression statement
+// whose leftmost operand left has already been parsed.
+func (p *Parser) parseExpressionStatementFrom(tok *token.Token, left ast.Expression) (*ast.ExpressionStatement, error) {
+	stmt := &ast.ExpressionStatement{Token: tok}
 
 	var err error
-	stmt.Expr, err = p.parseExpression(LOWEST)
+	stmt.Expr, err = p.parseInfix(left, LOWEST)
 	if err != nil {
 		return nil, err
 	}
@@ -203,36 +444,48 @@ func (p *Parser) parseExpressionStatement() (*ast.ExpressionStatement, error) {
 
 func (p *Parser) parseBlockStatement() (*ast.BlockStatement, error) {
 	block := &ast.BlockStatement{Token: p.curToken}
-
 	p.nextToken()
+	return p.parseBlockRest(block)
+}
 
-	for ; !p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF); p.nextToken() {
-		stmt, err := p.parseStatement()
-		if err != nil {
-			return nil, err
-		}
+// parseBlockRest parses the remaining statements of block up to its '}'.
+func (p *Parser) parseBlockRest(block *ast.BlockStatement) (*ast.BlockStatement, error) {
+	for !p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) {
+		block.Stmts = append(block.Stmts, p.parseStatementWithRecovery())
+	}
 
-		block.Stmts = append(block.Stmts, stmt)
+	if !p.curTokenIs(token.RBRACE) {
+		return nil, p.newError(p.curToken, "expected '}', got '%s' instead", p.curToken.Type)
 	}
+	block.Rbrace = p.curToken
 
 	return block, nil
 }
 
 func (p *Parser) parseExpression(precedence int) (ast.Expression, error) {
-	prefix, ok := p.prefixParseFns[p.curToken.Type]
-	if !ok {
-		return nil, fmt.Errorf("no prefix parse function for '%s' found", p.curToken.Type)
-	}
-
-	expr, err := prefix()
+	expr, err := p.parsePrefix()
 	if err != nil {
 		return nil, err
 	}
+	return p.parseInfix(expr, precedence)
+}
+
+func (p *Parser) parsePrefix() (ast.Expression, error) {
+	prefix, ok := p.prefixParseFns[p.curToken.Type]
+	if !ok {
+		return nil, p.newError(p.curToken, "no prefix parse function for '%s' found", p.curToken.Type)
+	}
+	return prefix()
+}
 
+// parseInfix parses the operators binding tighter than precedence that
+// follow expr.
+func (p *Parser) parseInfix(expr ast.Expression, precedence int) (ast.Expression, error) {
+	var err error
 	for !p.peekTokenIs(token.SEMICOLON) && precedence < p.peekPrecedence() {
 		infix, ok := p.infixParseFns[p.peekToken.Type]
 		if !ok {
-			return nil, fmt.Errorf("no infix parse function for '%s' found", p.peekToken.Type)
+			return nil, p.newError(p.peekToken, "no infix parse function for '%s' found", p.peekToken.Type)
 		}
 
 		p.nextToken()
@@ -299,60 +552,56 @@ func (p *Parser) parseShortCircuitExpression(left ast.Expression) (ast.Expressio
 }
 
 func (p *Parser) parsePrefixIncAndDec() (ast.Expression, error) {
-	res := &ast.PrefixIncAndDec{Token: p.curToken}
-	expr := &ast.Assignment{}
-	var op string
-	if p.curTokenIs(token.INC) {
-		op = "+"
-	} else {
-		op = "-"
+	res := &ast.PrefixIncAndDec{Token: p.curToken, Operator: token.PLUS}
+	if p.curTokenIs(token.DEC) {
+		res.Operator = token.MINUS
 	}
 	p.nextToken()
 
 	var err error
-	expr.Left, err = p.parseExpression(PREFIX)
+	res.Target, err = p.parseExpression(PREFIX)
 	if err != nil {
 		return nil, err
 	}
 
-	expr.Right = &ast.InfixExpression{
-		Left:     expr.Left,
-		Right:    &ast.IntegerLiteral{Value: 1},
-		Operator: op,
+	if err = checkAssignable(res.Target); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (p *Parser) parsePostfixIncAndDec(left ast.Expression) (ast.Expression, error) {
+	if err := checkAssignable(left); err != nil {
+		return nil, err
+	}
+
+	res := &ast.PostfixIncAndDec{Token: p.curToken, Operator: token.PLUS, Target: left}
+	if p.curTokenIs(token.DEC) {
+		res.Operator = token.MINUS
 	}
-	res.Expr = expr
 	return res, nil
 }
 
-// TODO
 func (p *Parser) parseAssignmentConverter(left ast.Expression) (ast.Expression, error) {
-	res := &ast.AssignmentConverter{Token: p.curToken}
-	expr := &ast.Assignment{Left: left}
+	if err := checkAssignable(left); err != nil {
+		return nil, err
+	}
 
-	var op string
-	switch p.curToken.Type {
-	case "+=":
-		op = "+"
-	case "-=":
-		op = "-"
-	default:
-		return nil, fmt.Errorf("unknown operator: '%s'", p.curToken.Type)
+	res := &ast.AssignmentConverter{
+		Token:    p.curToken,
+		Operator: compoundOperators[p.curToken.Type],
+		Left:     left,
 	}
 
 	p.nextToken()
 
 	var err error
-	expr.Right, err = p.parseExpression(LOWEST)
+	res.Right, err = p.parseExpression(LOWEST)
 	if err != nil {
 		return nil, err
 	}
 
-	expr.Right = &ast.InfixExpression{
-		Left:     left,
-		Operator: op,
-		Right:    expr.Right,
-	}
-	res.Expr = expr
 	return res, nil
 }
 
@@ -414,21 +663,311 @@ func (p *Parser) parseIfExpression() (ast.Expression, error) {
 	if p.peekTokenIs(token.ELSE) {
 		p.nextToken()
 
-		if err = p.expectPeek(token.LBRACE); err != nil {
+		if p.peekTokenIs(token.IF) {
+			p.nextToken()
+			expr.Alternative, err = p.parseIfExpression()
+		} else {
+			if err = p.expectPeek(token.LBRACE); err != nil {
+				return nil, err
+			}
+			expr.Alternative, err = p.parseBlockStatement()
+		}
+		if err != nil {
 			return nil, err
 		}
+	}
 
-		expr.Alternative, err = p.parseBlockStatement()
-		if err != nil {
+	return expr, nil
+}
+
+// parseSwitchExpression parses a switch. Each case runs the statements up to
+// the next case; there is no fallthrough.
+func (p *Parser) parseSwitchExpression() (ast.Expression, error) {
+	expr := &ast.SwitchExpression{Token: p.curToken}
+	var err error
+
+	if err = p.expectPeek(token.LPAREN); err != nil {
+		return nil, err
+	}
+	if expr.Subject, err = p.parseGroupedExpression(); err != nil {
+		return nil, err
+	}
+	if err = p.expectPeek(token.LBRACE); err != nil {
+		return nil, err
+	}
+	p.nextToken()
+
+	hasDefault := false
+	for !p.curTokenIs(token.RBRACE) {
+		c := &ast.SwitchCase{Token: p.curToken}
+		switch p.curToken.Type {
+		case token.CASE:
+			if p.peekTokenIs(token.COLON) {
+				return nil, p.newError(p.peekToken, "expected a value after 'case'")
+			}
+			if c.Values, err = p.parseExpressionList(token.COMMA, token.COLON); err != nil {
+				return nil, err
+			}
+		case token.DEFAULT:
+			if hasDefault {
+				return nil, p.newError(p.curToken, "multiple defaults in switch")
+			}
+			hasDefault = true
+			if err = p.expectPeek(token.COLON); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, p.newError(p.curToken, "expected 'case' or 'default', got '%s' instead", p.curToken.Type)
+		}
+
+		c.Body = p.parseCaseBody()
+		expr.Cases = append(expr.Cases, c)
+	}
+	expr.Rbrace = p.curToken
+
+	return expr, nil
+}
+
+// parseCaseBody parses the statements after the ':' of a case, the current
+// token, up to the next case or the closing '}'.
+func (p *Parser) parseCaseBody() *ast.BlockStatement {
+	body := &ast.BlockStatement{Token: p.curToken}
+	p.nextToken()
+	for !p.curTokenIs(token.CASE) && !p.curTokenIs(token.DEFAULT) &&
+		!p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) {
+		body.Stmts = append(body.Stmts, p.parseStatementWithRecovery())
+	}
+	return body
+}
+
+// parseMatchExpression parses a match. Like a switch, each case runs the
+// statements up to the next case.
+func (p *Parser) parseMatchExpression() (ast.Expression, error) {
+	expr := &ast.MatchExpression{Token: p.curToken}
+	var err error
+
+	if err = p.expectPeek(token.LPAREN); err != nil {
+		return nil, err
+	}
+	if expr.Subject, err = p.parseGroupedExpression(); err != nil {
+		return nil, err
+	}
+	if err = p.expectPeek(token.LBRACE); err != nil {
+		return nil, err
+	}
+	p.nextToken()
+
+	for !p.curTokenIs(token.RBRACE) {
+		if !p.curTokenIs(token.CASE) {
+			return nil, p.newError(p.curToken, "expected 'case', got '%s' instead", p.curToken.Type)
+		}
+		c := &ast.MatchCase{Token: p.curToken}
+
+		p.nextToken()
+		if c.Pattern, err = p.parsePattern(map[string]bool{}); err != nil {
+			return nil, err
+		}
+		if p.peekTokenIs(token.IF) {
+			p.nextToken()
+			p.nextToken()
+			if c.Guard, err = p.parseExpression(LOWEST); err != nil {
+				return nil, err
+			}
+		}
+		if err = p.expectPeek(token.COLON); err != nil {
 			return nil, err
 		}
+
+		c.Body = p.parseCaseBody()
+		expr.Cases = append(expr.Cases, c)
 	}
+	expr.Rbrace = p.curToken
 
 	return expr, nil
 }
 
-func (p *Parser) parseForLoopStatement() (*ast.ForLoopStatement, error) {
-	res := &ast.ForLoopStatement{Token: p.curToken}
+// parsePattern parses the pattern at the current token, adding the names it
+// binds to names.
+func (p *Parser) parsePattern(names map[string]bool) (ast.Pattern, error) {
+	switch p.curToken.Type {
+	case token.IDENT:
+		if p.peekTokenIs(token.LPAREN) {
+			return p.parseTypePattern(names)
+		}
+		return p.parseBindingPattern(names)
+	case token.INT, token.FLOAT, token.STRING, token.TRUE, token.FALSE:
+		value, err := p.parsePrefix()
+		if err != nil {
+			return nil, err
+		}
+		return &ast.LiteralPattern{Value: value}, nil
+	case token.MINUS:
+		if !p.peekTokenIs(token.INT) && !p.peekTokenIs(token.FLOAT) {
+			return nil, p.newError(p.peekToken, "expected a number after '-' in pattern, got '%s' instead", p.peekToken.Type)
+		}
+		expr := &ast.PrefixExpression{Token: p.curToken, Operator: p.curToken.Literal}
+		p.nextToken()
+		var err error
+		if expr.Right, err = p.parsePrefix(); err != nil {
+			return nil, err
+		}
+		return &ast.LiteralPattern{Value: expr}, nil
+	case token.LBRACKET:
+		return p.parseArrayPattern(names)
+	case token.LBRACE:
+		return p.parseHashPattern(names)
+	default:
+		return nil, p.newError(p.curToken, "expected a pattern, got '%s' instead", p.curToken.Type)
+	}
+}
+
+// parseBindingPattern parses the name at the current token, or the wildcard
+// '_'.
+func (p *Parser) parseBindingPattern(names map[string]bool) (ast.Pattern, error) {
+	if !p.curTokenIs(token.IDENT) {
+		return nil, p.newError(p.curToken, "expected a name, got '%s' instead", p.curToken.Type)
+	}
+	if p.curToken.Literal == "_" {
+		return &ast.WildcardPattern{Token: p.curToken}, nil
+	}
+
+	name := p.newIdentifier()
+	if names[name.Value] {
+		return nil, p.newError(p.curToken, "name '%s' bound more than once in pattern", name.Value)
+	}
+	names[name.Value] = true
+	return &ast.BindingPattern{Name: name}, nil
+}
+
+// parseRestPattern parses the binding after the '...' at the current token,
+// which must be the last element before end.
+func (p *Parser) parseRestPattern(names map[string]bool, end token.TokenType) (ast.Pattern, error) {
+	p.nextToken()
+	rest, err := p.parseBindingPattern(names)
+	if err != nil {
+		return nil, err
+	}
+	if p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+	}
+	if !p.peekTokenIs(end) {
+		return nil, p.newError(p.peekToken, "rest pattern must be last")
+	}
+	return rest, nil
+}
+
+func (p *Parser) parseArrayPattern(names map[string]bool) (*ast.ArrayPattern, error) {
+	pat := &ast.ArrayPattern{Token: p.curToken}
+
+	for !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+
+		var err error
+		if p.curTokenIs(token.ELLIPSIS) {
+			if pat.Rest, err = p.parseRestPattern(names, token.RBRACKET); err != nil {
+				return nil, err
+			}
+			break
+		}
+
+		elem, err := p.parsePattern(names)
+		if err != nil {
+			return nil, err
+		}
+		pat.Elements = append(pat.Elements, elem)
+
+		if !p.peekTokenIs(token.RBRACKET) {
+			if err = p.expectPeek(token.COMMA); err != nil {
+				return nil, err
+			}
+		}
+	}
+	p.nextToken()
+	pat.Rbracket = p.curToken
+
+	return pat, nil
+}
+
+// parseHashPattern parses a hash pattern. A bare name as a key stands for
+// the string, and alone for 'name: name'.
+func (p *Parser) parseHashPattern(names map[string]bool) (*ast.HashPattern, error) {
+	pat := &ast.HashPattern{Token: p.curToken}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+
+		var err error
+		if p.curTokenIs(token.ELLIPSIS) {
+			if pat.Rest, err = p.parseRestPattern(names, token.RBRACE); err != nil {
+				return nil, err
+			}
+			break
+		}
+
+		var key ast.Expression
+		var value ast.Pattern
+		switch p.curToken.Type {
+		case token.IDENT:
+			key = ast.NewStringLiteral(p.curToken, p.curToken.Literal)
+			if !p.peekTokenIs(token.COLON) {
+				if value, err = p.parseBindingPattern(names); err != nil {
+					return nil, err
+				}
+			}
+		case token.STRING, token.INT, token.TRUE, token.FALSE:
+			if key, err = p.parsePrefix(); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, p.newError(p.curToken, "expected a hash pattern key, got '%s' instead", p.curToken.Type)
+		}
+
+		if value == nil {
+			if err = p.expectPeek(token.COLON); err != nil {
+				return nil, err
+			}
+			p.nextToken()
+			if value, err = p.parsePattern(names); err != nil {
+				return nil, err
+			}
+		}
+		pat.Keys = append(pat.Keys, key)
+		pat.Values = append(pat.Values, value)
+
+		if !p.peekTokenIs(token.RBRACE) {
+			if err = p.expectPeek(token.COMMA); err != nil {
+				return nil, err
+			}
+		}
+	}
+	p.nextToken()
+	pat.Rbrace = p.curToken
+
+	return pat, nil
+}
+
+// parseTypePattern parses a type name followed by a parenthesized pattern.
+func (p *Parser) parseTypePattern(names map[string]bool) (*ast.TypePattern, error) {
+	pat := &ast.TypePattern{Type: p.newIdentifier()}
+	p.nextToken()
+	p.nextToken()
+
+	var err error
+	if pat.Pattern, err = p.parsePattern(names); err != nil {
+		return nil, err
+	}
+	if err = p.expectPeek(token.RPAREN); err != nil {
+		return nil, err
+	}
+	pat.Rparen = p.curToken
+
+	return pat, nil
+}
+
+// parseForStatement parses a C-style for loop or, if 'in' follows the loop
+// variables, a for-in loop.
+func (p *Parser) parseForStatement(label *ast.Identifier) (ast.Statement, error) {
+	res := &ast.ForLoopStatement{Token: p.curToken, Label: label}
 	var err error
 
 	if err = p.expectPeek(token.LPAREN); err != nil {
@@ -440,9 +979,12 @@ func (p *Parser) parseForLoopStatement() (*ast.ForLoopStatement, error) {
 		if res.Init, err = p.parseStatement(); err != nil {
 			return nil, err
 		}
+		if p.peekTokenIs(token.IN) {
+			return p.parseForInStatement(res.Token, label, res.Init)
+		}
 	}
 	if !p.curTokenIs(token.SEMICOLON) {
-		return nil, fmt.Errorf("expected ';', got '%s' instead", p.curToken.Type)
+		return nil, p.newError(p.curToken, "expected ';', got '%s' instead", p.curToken.Type)
 	}
 
 	if !p.peekTokenIs(token.SEMICOLON) {
@@ -468,6 +1010,7 @@ func (p *Parser) parseForLoopStatement() (*ast.ForLoopStatement, error) {
 		return nil, err
 	}
 
+	defer p.enterLoop(label)()
 	res.Body, err = p.parseBlockStatement()
 	if err != nil {
 		return nil, err
@@ -476,60 +1019,215 @@ func (p *Parser) parseForLoopStatement() (*ast.ForLoopStatement, error) {
 	return res, nil
 }
 
-func (p *Parser) parseFunctionLiteral() (ast.Expression, error) {
-	lit := &ast.FunctionLiteral{Token: p.curToken}
+// parseForInStatement parses the rest of a for-in loop after its loop
+// variables, given as the initialization of a C-style loop: 'x', 'i, x' or
+// the same after 'let'.
+func (p *Parser) parseForInStatement(tok *token.Token, label *ast.Identifier, vars ast.Statement) (*ast.ForInStatement, error) {
+	res := &ast.ForInStatement{Token: tok, Label: label}
+
+	var expr ast.Expression
+	switch vars := vars.(type) {
+	case *ast.ExpressionStatement:
+		expr = vars.Expr
+	case *ast.LetStatement:
+		expr = vars.Value
+	}
+
+	switch expr := expr.(type) {
+	case *ast.Identifier:
+		res.Value = expr
+	case *ast.ExpressionList:
+		if len(expr.Exprs) != 2 {
+			break
+		}
+		key, ok := expr.Exprs[0].(*ast.Identifier)
+		value, ok2 := expr.Exprs[1].(*ast.Identifier)
+		if ok && ok2 {
+			res.Key, res.Value = key, value
+		}
+	}
+	if res.Value == nil {
+		return nil, token.NewError(vars.Pos(), vars.End(), "expected one or two loop variables before 'in'")
+	}
+
+	p.nextToken()
+	p.nextToken()
+
+	var err error
+	if res.Iterable, err = p.parseExpression(LOWEST); err != nil {
+		return nil, err
+	}
+	if err = p.expectPeek(token.RPAREN); err != nil {
+		return nil, err
+	}
+	if err = p.expectPeek(token.LBRACE); err != nil {
+		return nil, err
+	}
+
+	defer p.enterLoop(label)()
+	res.Body, err = p.parseBlockStatement()
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (p *Parser) parseWhileStatement(label *ast.Identifier) (*ast.WhileStatement, error) {
+	res := &ast.WhileStatement{Token: p.curToken, Label: label}
 	var err error
 
 	if err = p.expectPeek(token.LPAREN); err != nil {
 		return nil, err
 	}
+	if res.Condition, err = p.parseGroupedExpression(); err != nil {
+		return nil, err
+	}
+	if err = p.expectPeek(token.LBRACE); err != nil {
+		return nil, err
+	}
 
-	lit.Params, err = p.parseIdentifierList(token.COMMA, token.RPAREN)
+	defer p.enterLoop(label)()
+	res.Body, err = p.parseBlockStatement()
 	if err != nil {
 		return nil, err
 	}
 
+	return res, nil
+}
+
+func (p *Parser) parseDoWhileStatement(label *ast.Identifier) (*ast.DoWhileStatement, error) {
+	res := &ast.DoWhileStatement{Token: p.curToken, Label: label}
+	var err error
+
 	if err = p.expectPeek(token.LBRACE); err != nil {
 		return nil, err
 	}
-
-	lit.Body, err = p.parseBlockStatement()
+	exit := p.enterLoop(label)
+	res.Body, err = p.parseBlockStatement()
+	exit()
 	if err != nil {
 		return nil, err
 	}
 
-	return lit, nil
+	if err = p.expectPeek(token.WHILE); err != nil {
+		return nil, err
+	}
+	if err = p.expectPeek(token.LPAREN); err != nil {
+		return nil, err
+	}
+	if res.Condition, err = p.parseGroupedExpression(); err != nil {
+		return nil, err
+	}
+	res.Rparen = p.curToken
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return res, nil
 }
 
-func (p *Parser) parseIdentifierList(sep, end token.TokenType) ([]*ast.Identifier, error) {
-	identifiers := []*ast.Identifier{}
+func (p *Parser) parseFunctionDeclaration() (*ast.FunctionDeclaration, error) {
+	lit := &ast.FunctionLiteral{Token: p.curToken}
+	p.nextToken()
+	lit.Name = p.newIdentifier()
+
+	if err := p.parseFunction(lit); err != nil {
+		return nil, err
+	}
 
-	if p.peekTokenIs(end) {
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
-		return identifiers, nil
 	}
 
-	if err := p.expectPeek(token.IDENT); err != nil {
+	return &ast.FunctionDeclaration{Func: lit}, nil
+}
+
+func (p *Parser) parseFunctionLiteral() (ast.Expression, error) {
+	lit := &ast.FunctionLiteral{Token: p.curToken}
+	if err := p.parseFunction(lit); err != nil {
 		return nil, err
 	}
-	identifiers = append(identifiers, p.newIdentifier())
+	return lit, nil
+}
 
-	for !p.peekTokenIs(end) {
-		if err := p.expectPeek(sep); err != nil {
-			return nil, err
+// parseFunction parses the parameters and body of lit, the current token
+// being the one before the '('.
+func (p *Parser) parseFunction(lit *ast.FunctionLiteral) error {
+	if err := p.expectPeek(token.LPAREN); err != nil {
+		return err
+	}
+
+	if err := p.parseParameters(lit); err != nil {
+		return err
+	}
+
+	if err := p.expectPeek(token.LBRACE); err != nil {
+		return err
+	}
+
+	// Loops around a function do not extend into its body.
+	loops := p.loops
+	p.loops = nil
+	defer func() { p.loops = loops }()
+
+	var err error
+	lit.Body, err = p.parseBlockStatement()
+	return err
+}
+
+// parseParameters parses the parameters of lit up to the closing ')':
+// required ones, then ones with a default value, then an optional rest one.
+func (p *Parser) parseParameters(lit *ast.FunctionLiteral) error {
+	lit.Params = []*ast.Identifier{}
+	hasDefaults := false
+
+	for !p.peekTokenIs(token.RPAREN) {
+		if lit.Rest != nil {
+			return p.newError(lit.Rest.Token, "rest parameter must be last")
+		}
+		if len(lit.Params) > 0 {
+			if err := p.expectPeek(token.COMMA); err != nil {
+				return err
+			}
+		}
+
+		if p.peekTokenIs(token.ELLIPSIS) {
+			p.nextToken()
+			if err := p.expectPeek(token.IDENT); err != nil {
+				return err
+			}
+			lit.Rest = p.newIdentifier()
+			continue
 		}
 
 		if err := p.expectPeek(token.IDENT); err != nil {
-			return nil, err
+			return err
+		}
+		param := p.newIdentifier()
+
+		var def ast.Expression
+		if p.peekTokenIs(token.ASSIGN) {
+			p.nextToken()
+			p.nextToken()
+
+			var err error
+			def, err = p.parseExpression(precedences[token.COMMA])
+			if err != nil {
+				return err
+			}
+			hasDefaults = true
+		} else if hasDefaults {
+			return p.newError(param.Token, "parameter '%s' without default follows parameter with default", param.Value)
 		}
-		identifiers = append(identifiers, p.newIdentifier())
-	}
 
-	if err := p.expectPeek(end); err != nil {
-		return nil, err
+		lit.Params = append(lit.Params, param)
+		lit.Defaults = append(lit.Defaults, def)
 	}
 
-	return identifiers, nil
+	p.nextToken()
+	return nil
 }
 
 func (p *Parser) parseCallExpression(function ast.Expression) (ast.Expression, error) {
@@ -540,6 +1238,7 @@ func (p *Parser) parseCallExpression(function ast.Expression) (ast.Expression, e
 	if err != nil {
 		return nil, err
 	}
+	expr.Rparen = p.curToken
 
 	return expr, nil
 }
@@ -571,6 +1270,24 @@ func (p *Parser) parseCommaExpression(left ast.Expression) (ast.Expression, erro
 	return res, nil
 }
 
+// parseElement parses an element of a call's arguments or an array literal,
+// which may be spread with '...'.
+func (p *Parser) parseElement(precedence int) (ast.Expression, error) {
+	if !p.curTokenIs(token.ELLIPSIS) {
+		return p.parseExpression(precedence)
+	}
+
+	spread := &ast.SpreadExpression{Token: p.curToken}
+	p.nextToken()
+
+	var err error
+	spread.Value, err = p.parseExpression(precedence)
+	if err != nil {
+		return nil, err
+	}
+	return spread, nil
+}
+
 func (p *Parser) parseExpressionList(sep, end token.TokenType) ([]ast.Expression, error) {
 	list := []ast.Expression{}
 
@@ -582,7 +1299,7 @@ func (p *Parser) parseExpressionList(sep, end token.TokenType) ([]ast.Expression
 	p.nextToken()
 
 	precedence := precedences[sep]
-	expr, err := p.parseExpression(precedence)
+	expr, err := p.parseElement(precedence)
 	if err != nil {
 		return nil, err
 	}
@@ -596,7 +1313,7 @@ func (p *Parser) parseExpressionList(sep, end token.TokenType) ([]ast.Expression
 
 		p.nextToken()
 
-		expr, err = p.parseExpression(precedence)
+		expr, err = p.parseElement(precedence)
 		if err != nil {
 			return nil, err
 		}
@@ -617,29 +1334,119 @@ func (p *Parser) parseArrayLiteral() (ast.Expression, error) {
 	if err != nil {
 		return nil, err
 	}
+	array.Rbracket = p.curToken
 
 	return array, nil
 }
 
-// TODO: SliceExpression
+func (p *Parser) parseHashLiteral() (ast.Expression, error) {
+	return p.parseHashPairs(&ast.HashLiteral{Token: p.curToken}, nil)
+}
+
+// parseHashPairs parses the pairs of hash up to its '}'. A non-nil key is the
+// already parsed first key, the current token being the ':' after it.
+func (p *Parser) parseHashPairs(hash *ast.HashLiteral, key ast.Expression) (*ast.HashLiteral, error) {
+	precedence := precedences[token.COMMA]
+
+	for key != nil || !p.peekTokenIs(token.RBRACE) {
+		var err error
+		if key == nil {
+			p.nextToken()
+
+			key, err = p.parseExpression(precedence)
+			if err != nil {
+				return nil, err
+			}
+
+			if err = p.expectPeek(token.COLON); err != nil {
+				return nil, err
+			}
+		}
+
+		p.nextToken()
+
+		value, err := p.parseExpression(precedence)
+		if err != nil {
+			return nil, err
+		}
+
+		hash.Pairs = append(hash.Pairs, &ast.HashPair{Key: key, Value: value})
+		key = nil
+
+		if !p.peekTokenIs(token.RBRACE) {
+			if err = p.expectPeek(token.COMMA); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	p.nextToken()
+	hash.Rbrace = p.curToken
+
+	return hash, nil
+}
+
 func (p *Parser) parseIndexExpression(left ast.Expression) (ast.Expression, error) {
-	expr := &ast.IndexExpression{Token: p.curToken, Left: left}
+	tok := p.curToken
 
 	p.nextToken()
 
+	var low ast.Expression
+	if !p.curTokenIs(token.COLON) {
+		index, err := p.parseExpression(LOWEST)
+		if err != nil {
+			return nil, err
+		}
+
+		if !p.peekTokenIs(token.COLON) {
+			if err = p.expectPeek(token.RBRACKET); err != nil {
+				return nil, err
+			}
+			return &ast.IndexExpression{Token: tok, Left: left, Indices: index, Rbracket: p.curToken}, nil
+		}
+
+		p.nextToken()
+		low = index
+	}
+
+	return p.parseSliceExpression(&ast.SliceExpression{Token: tok, Left: left, Low: low})
+}
+
+// parseSliceExpression parses the rest of expr after the ':' following its
+// lower bound.
+func (p *Parser) parseSliceExpression(expr *ast.SliceExpression) (ast.Expression, error) {
 	var err error
-	expr.Indices, err = p.parseExpression(LOWEST)
+	expr.High, err = p.parseSliceBound()
 	if err != nil {
 		return nil, err
 	}
 
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		expr.Step, err = p.parseSliceBound()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if err = p.expectPeek(token.RBRACKET); err != nil {
 		return nil, err
 	}
+	expr.Rbracket = p.curToken
 
 	return expr, nil
 }
 
+// parseSliceBound parses the slice bound after the current ':', returning nil
+// if it is omitted.
+func (p *Parser) parseSliceBound() (ast.Expression, error) {
+	if p.peekTokenIs(token.COLON) || p.peekTokenIs(token.RBRACKET) {
+		return nil, nil
+	}
+	p.nextToken()
+	return p.parseExpression(LOWEST)
+}
+
 func (p *Parser) parseIdentifier() (ast.Expression, error) {
 	return p.newIdentifier(), nil
 }
@@ -654,12 +1461,25 @@ func (p *Parser) parseStringLiteral() (ast.Expression, error) {
 
 func (p *Parser) parseIntegerLiteral() (ast.Expression, error) {
 	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		if v, ok := new(big.Int).SetString(p.curToken.Literal, 0); ok {
+			return &ast.IntegerLiteral{Token: p.curToken, Big: v}, nil
+		}
+	}
 	if err != nil {
-		return nil, fmt.Errorf("could not parse '%s' as integer", p.curToken.Literal)
+		return nil, p.newError(p.curToken, "could not parse '%s' as integer", p.curToken.Literal)
 	}
 	return ast.NewIntegerLiteral(p.curToken, value), nil
 }
 
+func (p *Parser) parseFloatLiteral() (ast.Expression, error) {
+	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	if err != nil {
+		return nil, p.newError(p.curToken, "could not parse '%s' as float", p.curToken.Literal)
+	}
+	return &ast.FloatLiteral{Token: p.curToken, Value: value}, nil
+}
+
 func (p *Parser) parseBoolean() (ast.Expression, error) {
 	return ast.NewBoolean(p.curToken, p.curTokenIs(token.TRUE)), nil
 }
@@ -671,6 +1491,20 @@ func (p *Parser) Errors() []error {
 func (p *Parser) nextToken() {
 	p.curToken = p.peekToken
 	p.peekToken = p.l.NextToken()
+	if p.curToken == nil {
+		return
+	}
+
+	switch p.curToken.Type {
+	case token.LPAREN, token.LBRACKET:
+		p.parens++
+	case token.RPAREN, token.RBRACKET:
+		p.parens--
+	case token.LBRACE:
+		p.braces++
+	case token.RBRACE:
+		p.braces--
+	}
 }
 
 func (p *Parser) curTokenIs(tp token.TokenType) bool  { return p.curToken.Type == tp }
@@ -681,7 +1515,20 @@ func (p *Parser) expectPeek(tp token.TokenType) error {
 		p.nextToken()
 		return nil
 	}
-	return fmt.Errorf("expected next token to be '%s', got '%s' instead", tp, p.peekToken.Type)
+	return p.newError(p.peekToken, "expected next token to be '%s', got '%s' instead", tp, p.peekToken.Type)
+}
+
+// newError returns an error located at tok. An error at an ILLEGAL token is
+// reported as the lexical error that caused it instead.
+func (p *Parser) newError(tok *token.Token, format string, a ...any) error {
+	if tok.Type == token.ILLEGAL {
+		for _, err := range p.l.Errors() {
+			if e := err.(*token.Error); e.Pos == tok.Pos {
+				return e
+			}
+		}
+	}
+	return token.NewError(tok.Pos, tok.End, fmt.Sprintf(format, a...))
 }
 
 func (p *Parser) curPrecedence() int {
@@ -692,12 +1539,39 @@ func (p *Parser) curPrecedence() int {
 }
 
 func (p *Parser) peekPrecedence() int {
+	// '++' and '--' starting a line are the prefix operators of a new statement.
+	if p.peekTokenIs(token.INC) || p.peekTokenIs(token.DEC) {
+		if p.peekToken.Pos.Line != p.curToken.End.Line {
+			return LOWEST
+		}
+	}
+
 	if p, ok := precedences[p.peekToken.Type]; ok {
 		return p
 	}
 	return LOWEST
 }
 
+// checkAssignable returns an error located at e unless e is an identifier or
+// an index expression.
+func checkAssignable(e ast.Expression) error {
+	var what string
+	switch e.(type) {
+	case *ast.Identifier, *ast.IndexExpression, *ast.SliceExpression:
+		return nil
+	case *ast.CallExpression:
+		what = "function call"
+	case *ast.IntegerLiteral, *ast.FloatLiteral, *ast.StringLiteral, *ast.Boolean,
+		*ast.ArrayLiteral, *ast.HashLiteral, *ast.FunctionLiteral:
+		what = "literal"
+	case *ast.ExpressionList:
+		what = "expression list"
+	default:
+		what = "expression"
+	}
+	return token.NewError(e.Pos(), e.End(), fmt.Sprintf("cannot assign to %s", what))
+}
+
 func (p *Parser) registerPrefix(tp token.TokenType, fn prefixParseFn) {
 	p.prefixParseFns[tp] = fn
 }
@@ -122,6 +122,38 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestFloatLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"1.5;", 1.5},
+		{"0.25;", 0.25},
+		{"2e3;", 2000},
+		{"1.5E-2;", 0.015},
+		{"3e+1;", 30},
+	}
+
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Stmts[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Stmts[0])
+		}
+
+		literal, ok := stmt.Expr.(*ast.FloatLiteral)
+		if !ok {
+			t.Fatalf("expr not *ast.FloatLiteral. got=%T", stmt.Expr)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("literal.Value not %g. got=%g", tt.expected, literal.Value)
+		}
+	}
+}
+
 func TestParsingPrefixExpressions(t *testing.T) {
 	prefixTests := []struct {
 		input    string
@@ -330,6 +362,34 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"add(a * b[2], b[1], 2 * [1, 2][1])",
 			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])));",
 		},
+		{
+			"a += b * c",
+			"(a += (b * c));",
+		},
+		{
+			"a[i] <<= 1 + 2",
+			"((a[i]) <<= (1 + 2));",
+		},
+		{
+			"a ^= b | c",
+			"(a ^= (b | c));",
+		},
+		{
+			"++a[0] * 2",
+			"((++(a[0])) * 2);",
+		},
+		{
+			"a++ * 2",
+			"((a++) * 2);",
+		},
+		{
+			"-a[0]--",
+			"(-((a[0])--));",
+		},
+		{
+			"a\n++b",
+			"a;(++b);",
+		},
 	}
 
 	for _, tt := range tests {
@@ -470,15 +530,20 @@ func TestIfElseExpression(t *testing.T) {
 		return
 	}
 
-	if len(expr.Alternative.Stmts) != 1 {
+	block, ok := expr.Alternative.(*ast.BlockStatement)
+	if !ok {
+		t.Fatalf("expr.Alternative is not ast.BlockStatement. got=%T", expr.Alternative)
+	}
+
+	if len(block.Stmts) != 1 {
 		t.Errorf("expr.Alternative.Statements does not contain 1 statements. got=%d\n",
-			len(expr.Alternative.Stmts))
+			len(block.Stmts))
 	}
 
-	alternative, ok := expr.Alternative.Stmts[0].(*ast.ExpressionStatement)
+	alternative, ok := block.Stmts[0].(*ast.ExpressionStatement)
 	if !ok {
 		t.Fatalf("Statements[0] is not ast.ExpressionStatement. got=%T",
-			expr.Alternative.Stmts[0])
+			block.Stmts[0])
 	}
 
 	if !testIdentifier(t, alternative.Expr, "y") {
@@ -561,6 +626,61 @@ func TestFunctionParameterParsing(t *testing.T) {
 	}
 }
 
+func TestFunctionDefaultAndRestParameters(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn(a, b = 2) {}", "fn(a, b = 2) {  }"},
+		{"fn(a = 1 + 2, ...rest) {}", "fn(a = (1 + 2), ...rest) {  }"},
+		{"fn(...args) { args }", "fn(...args) { args; }"},
+		{"f(...xs, 1)", "f(...xs, 1)"},
+		{"[0, ...a + b]", "[0, ...(a + b)]"},
+	}
+
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Stmts[0].(*ast.ExpressionStatement)
+		if actual := stmt.Expr.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestFunctionDeclarationParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		isDecl   bool
+		expected string
+	}{
+		{"fn add(a, b) { a + b }", true, "fn add(a, b) { (a + b); }"},
+		{"fn f(x = 1) {};", true, "fn f(x = 1) {  }"},
+		{"fn(x) { x }(5)", false, "fn(x) { x; }(5);"},
+	}
+
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Stmts) != 1 {
+			t.Fatalf("program.Stmts does not contain 1 statement. got=%d", len(program.Stmts))
+		}
+
+		_, ok := program.Stmts[0].(*ast.FunctionDeclaration)
+		if ok != tt.isDecl {
+			t.Errorf("%q: wrong statement type. got=%T", tt.input, program.Stmts[0])
+		}
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
 func TestCallExpressionParsing(t *testing.T) {
 	input := "add(1, 2 * 3, 4 + 5);"
 
@@ -681,6 +801,64 @@ func TestParsingArrayLiterals(t *testing.T) {
 	testInfixExpression(t, array.Elements[2], 3, "+", 3)
 }
 
+func TestParsingHashLiterals(t *testing.T) {
+	input := `{"one": 1, "two": 2 * 2, 3: 3 + 3}`
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Stmts[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("stmt is not ast.ExpressionStatement. got=%T", program.Stmts[0])
+	}
+
+	hash, ok := stmt.Expr.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("expr not ast.HashLiteral. got=%T", stmt.Expr)
+	}
+
+	if len(hash.Pairs) != 3 {
+		t.Fatalf("len(hash.Pairs) not 3. got=%d", len(hash.Pairs))
+	}
+
+	for i, key := range []string{"one", "two"} {
+		str, ok := hash.Pairs[i].Key.(*ast.StringLiteral)
+		if !ok || str.Value != key {
+			t.Errorf("key %d is not %q. got=%s", i, key, hash.Pairs[i].Key)
+		}
+	}
+
+	testIntegerLiteral(t, hash.Pairs[0].Value, 1)
+	testInfixExpression(t, hash.Pairs[1].Value, 2, "*", 2)
+	testIntegerLiteral(t, hash.Pairs[2].Key, 3)
+	testInfixExpression(t, hash.Pairs[2].Value, 3, "+", 3)
+}
+
+func TestParsingEmptyHashLiteral(t *testing.T) {
+	input := "{}"
+
+	l := lexer.NewLexer(input)
+	p := NewParser(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Stmts[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("stmt is not ast.ExpressionStatement. got=%T", program.Stmts[0])
+	}
+
+	hash, ok := stmt.Expr.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("expr not ast.HashLiteral. got=%T", stmt.Expr)
+	}
+
+	if len(hash.Pairs) != 0 {
+		t.Errorf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
+	}
+}
+
 func TestParsingIndexExpressions(t *testing.T) {
 	input := "myArray[1 + 1]"
 
@@ -708,6 +886,347 @@ func TestParsingIndexExpressions(t *testing.T) {
 	}
 }
 
+func TestParsingSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a[1:2]", "(a[1:2])"},
+		{"a[1:]", "(a[1:])"},
+		{"a[:n - 1]", "(a[:(n - 1)])"},
+		{"a[:]", "(a[:])"},
+		{"a[::2]", "(a[::2])"},
+		{"a[1:2:-1]", "(a[1:2:(-1)])"},
+		{"a[::]", "(a[:])"},
+		{"a[1:][0]", "((a[1:])[0])"},
+		{"a[i:j] = b[:]", "((a[i:j]) = (b[:]))"},
+	}
+
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Stmts[0].(*ast.ExpressionStatement)
+		if actual := stmt.Expr.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestBranchStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"for (;;) { break }", "for (;;) { break; }"},
+		{"for (;;) { continue; }", "for (;;) { continue; }"},
+		{
+			"outer: for (;;) { for (;;) { break outer; continue outer } }",
+			"outer: for (;;) { for (;;) { break outer; continue outer; } }",
+		},
+		{"outer: for (;;) { break\nouter }", "outer: for (;;) { break; outer; }"},
+	}
+
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		actual := program.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestBraceStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		isBlock  bool
+		expected string
+	}{
+		{"{ let x = 1; x }", true, "{ let (x = 1); x; }"},
+		{"{ x = 1 }", true, "{ (x = 1); }"},
+		{"{ a: for (;;) { break a } }", true, "{ a: for (;;) { break a; } }"},
+		{"{ {} }", true, "{ {}; }"},
+		{"{}", false, "{};"},
+		{"{a: 1}", false, "{a: 1};"},
+		{`{"a": 1}["a"] + 1`, false, "(({a: 1}[a]) + 1);"},
+	}
+
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Stmts) != 1 {
+			t.Fatalf("program.Stmts does not contain 1 statement. got=%d", len(program.Stmts))
+		}
+
+		_, ok := program.Stmts[0].(*ast.BlockStatement)
+		if ok != tt.isBlock {
+			t.Errorf("%q: wrong statement type. got=%T", tt.input, program.Stmts[0])
+		}
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestForInStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"for (x in xs) { x }", "for (x in xs) { x; }"},
+		{"for (i, x in xs) {}", "for (i, x in xs) {  }"},
+		{"for (let x in range(3)) {}", "for (x in range(3)) {  }"},
+		{"for (let k, v in h) {}", "for (k, v in h) {  }"},
+		{"l: for (x in xs) { break l }", "l: for (x in xs) { break l; }"},
+	}
+
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if _, ok := program.Stmts[0].(*ast.ForInStatement); !ok {
+			t.Errorf("%q: not *ast.ForInStatement. got=%T", tt.input, program.Stmts[0])
+		}
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestElseIfExpression(t *testing.T) {
+	input := "if (a) { 1 } else if (b) { 2 } else { 3 }"
+
+	p := NewParser(lexer.NewLexer(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Stmts[0].(*ast.ExpressionStatement)
+	expr, ok := stmt.Expr.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("stmt.Expr is not ast.IfExpression. got=%T", stmt.Expr)
+	}
+	elseIf, ok := expr.Alternative.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("expr.Alternative is not ast.IfExpression. got=%T", expr.Alternative)
+	}
+	if _, ok := elseIf.Alternative.(*ast.BlockStatement); !ok {
+		t.Fatalf("elseIf.Alternative is not ast.BlockStatement. got=%T", elseIf.Alternative)
+	}
+
+	expected := "if (a) { 1; } else if (b) { 2; } else { 3; };"
+	if actual := program.String(); actual != expected {
+		t.Errorf("expected=%q, got=%q", expected, actual)
+	}
+}
+
+func TestSwitchExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"switch (x) { case 1: a }", "switch (x) { case 1: a; };"},
+		{"switch (x) { case 1, 2: a; b case 3: default: c }", "switch (x) { case 1, 2: a; b; case 3: default: c; };"},
+		{"switch (x) {}", "switch (x) {  };"},
+		{"let y = switch (x + 1) { default: 0 }", "let (y = switch ((x + 1)) { default: 0; });"},
+		{"switch (x) { case ...xs: if (a) { b } }", "switch (x) { case ...xs: if (a) { b; }; };"},
+	}
+
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestMatchExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"match (x) { case 1: a case _: b }", "match (x) { case 1: a; case _: b; };"},
+		{`match (x) { case -1.5: a case "s": b case true: c }`, "match (x) { case (-1.5): a; case s: b; case true: c; };"},
+		{"match (x) { case [a, [b], ...rest]: a }", "match (x) { case [a, [b], ...rest]: a; };"},
+		{"match (x) { case [a, ..._,]: a case []: b }", "match (x) { case [a, ..._]: a; case []: b; };"},
+		{`match (x) { case {name: n, "age": a, 1: b, kind, ...rest}: n }`, "match (x) { case {name: n, age: a, 1: b, kind: kind, ...rest}: n; };"},
+		{"match (x) { case INTEGER(n) if n > 0: n }", "match (x) { case INTEGER(n) if (n > 0): n; };"},
+		{"let y = match (x) { case n: n }", "let (y = match (x) { case n: n; });"},
+	}
+
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestWhileStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"while (x < 3) { x += 1 }", "while ((x < 3)) { (x += 1); }"},
+		{"l: while (true) { break l }", "l: while (true) { break l; }"},
+		{"do { x } while (x)", "do { x; } while (x)"},
+		{"do { continue } while (false); x", "do { continue; } while (false)x;"},
+		{"{ l: do {} while (a) }", "{ l: do {  } while (a) }"},
+	}
+
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestParserErrorPosition(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = (1 + 2", "1:15: expected next token to be ')', got 'EOF' instead"},
+		{"let a = 1;\nlet b = )", "2:9: no prefix parse function for ')' found"},
+		{`let s = "abc`, "1:9: unterminated string literal"},
+		{`let s = "a\qc";`, `1:9: unknown escape sequence '\q'`},
+		{`let s = 1 "a\qc";`, `1:11: unknown escape sequence '\q'`},
+		{"let é = 1 @ 2;", `1:11: illegal character "@"`},
+		{"f()++", "1:1: cannot assign to function call"},
+		{"--1", "1:3: cannot assign to literal"},
+		{"++(a + b)", "1:4: cannot assign to expression"},
+		{"a, b += 1", "1:1: cannot assign to expression list"},
+		{"break", "1:1: 'break' outside loop"},
+		{"for (;;) { fn() { continue } }", "1:19: 'continue' outside loop"},
+		{"for (;;) { break outer }", "1:18: undefined loop label 'outer'"},
+		{"a: for (;;) { a: for (;;) {} }", "1:15: label 'a' already defined"},
+		{"x: let a = 1", "1:4: label 'x' must be followed by a loop"},
+		{"fn(a = 1, b) {}", "1:11: parameter 'b' without default follows parameter with default"},
+		{"fn(...a, b) {}", "1:7: rest parameter must be last"},
+		{"fn(a, ...) {}", "1:10: expected next token to be 'IDENT', got ')' instead"},
+		{"fn f {}", "1:6: expected next token to be '(', got '{' instead"},
+		{"a[1:2:3:4]", "1:8: expected next token to be ']', got ':' instead"},
+		{"for (a + b in xs) {}", "1:6: expected one or two loop variables before 'in'"},
+		{"for (a, b, c in xs) {}", "1:6: expected one or two loop variables before 'in'"},
+		{"for (x in xs) x", "1:15: expected next token to be '{', got 'IDENT' instead"},
+		{"if (a) {} else if b {}", "1:19: expected next token to be '(', got 'IDENT' instead"},
+		{"switch (x) { 1: a }", "1:14: expected 'case' or 'default', got 'INT' instead"},
+		{"switch (x) { case: a }", "1:18: expected a value after 'case'"},
+		{"switch (x) { default: a default: b }", "1:25: multiple defaults in switch"},
+		{"switch (x) { case 1 a }", "1:21: expected next token to be ',', got 'IDENT' instead"},
+		{"match (x) { default: a }", "1:13: expected 'case', got 'DEFAULT' instead"},
+		{"match (x) { case a + 1: a }", "1:20: expected next token to be ':', got '+' instead"},
+		{"match (x) { case -a: a }", "1:19: expected a number after '-' in pattern, got 'IDENT' instead"},
+		{"match (x) { case [a, a]: a }", "1:22: name 'a' bound more than once in pattern"},
+		{"match (x) { case [...a, b]: a }", "1:25: rest pattern must be last"},
+		{"match (x) { case [...1]: a }", "1:22: expected a name, got 'INT' instead"},
+		{"match (x) { case {f(): a}: a }", "1:20: expected next token to be ',', got '(' instead"},
+		{"match (x) { case {[a]: a}: a }", "1:19: expected a hash pattern key, got '[' instead"},
+		{"match (x) { case (a): a }", "1:18: expected a pattern, got '(' instead"},
+		{"while x {}", "1:7: expected next token to be '(', got 'IDENT' instead"},
+		{"do {} until (x)", "1:7: expected next token to be 'WHILE', got 'IDENT' instead"},
+		{"do {} while (x) break", "1:17: 'break' outside loop"},
+	}
+
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Fatalf("expected parser errors for %q", tt.input)
+		}
+
+		if errors[0].Error() != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, errors[0].Error())
+		}
+	}
+}
+
+func TestParserErrorRecovery(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedErrors []string
+		expected       string
+	}{
+		{
+			`let f = fn(x) {
+  let y = x +;
+  return y
+}
+f(1)`,
+			[]string{"2:14: no prefix parse function for ';' found"},
+			"let (f = fn(x) { <bad statement>; return y; });f(1);",
+		},
+		{
+			"let a = ; let b = 2; }; let c = )",
+			[]string{
+				"1:9: no prefix parse function for ';' found",
+				"1:22: no prefix parse function for '}' found",
+				"1:33: no prefix parse function for ')' found",
+			},
+			"<bad statement>;let (b = 2);<bad statement>;<bad statement>;",
+		},
+		{
+			"let x = (1 + 2\nlet y = [3; let z = 4",
+			[]string{
+				"2:1: expected next token to be ')', got 'LET' instead",
+				"2:11: expected next token to be ',', got ';' instead",
+			},
+			"<bad statement>;<bad statement>;let (z = 4);",
+		},
+		{
+			"for (let i = 0 i < 3; ++i) { i }\nlet x = 1",
+			[]string{"1:14: expected ';', got 'INT' instead"},
+			"<bad statement>;let (x = 1);",
+		},
+		{
+			"fn() { if (x) { 1 }",
+			[]string{"1:20: expected '}', got 'EOF' instead"},
+			"<bad statement>;",
+		},
+	}
+
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer(tt.input))
+		program := p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != len(tt.expectedErrors) {
+			t.Errorf("wrong number of errors for %q. expected=%d, got=%d (%v)",
+				tt.input, len(tt.expectedErrors), len(errors), errors)
+			continue
+		}
+
+		for i, err := range errors {
+			if err.Error() != tt.expectedErrors[i] {
+				t.Errorf("wrong error. expected=%q, got=%q", tt.expectedErrors[i], err.Error())
+			}
+		}
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
 func testInfixExpression(t *testing.T, expr ast.Expression, left interface{},
 	operator string, right interface{}) bool {
 
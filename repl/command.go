@@ -0,0 +1,131 @@
+package repl
+
+import (
+	"errors"
+	"fmt"
+	"go-interpreter/ast"
+	"go-interpreter/lexer"
+	"go-interpreter/object"
+	"go-interpreter/runner"
+	"go-interpreter/token"
+	"io/fs"
+	"runtime"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type command struct {
+	name, args, help string
+	takesCode        bool // the argument is code that may span several lines
+	run              func(s *session, arg string)
+}
+
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{name: ":load", args: "<file>", help: "evaluate a file into the current environment", run: (*session).load},
+		{name: ":env", help: "list the bindings of the current environment", run: (*session).listEnv},
+		{name: ":tokens", args: "<code>", help: "show the tokens of code", takesCode: true, run: (*session).dumpTokens},
+		{name: ":ast", args: "<code>", help: "show the syntax tree of code", takesCode: true, run: (*session).dumpAST},
+		{name: ":reset", help: "start over with a fresh environment", run: (*session).reset},
+		{name: ":time", args: "<code>", help: "evaluate code and report wall time and allocations", takesCode: true, run: (*session).time},
+		{name: ":help", help: "show this help", run: (*session).help},
+	}
+}
+
+// splitCommand splits a ':cmd arg' input into the command and its argument.
+func splitCommand(t string) (*command, string, bool) {
+	t = strings.TrimSpace(t)
+	name, arg := t, ""
+	if i := strings.IndexFunc(t, unicode.IsSpace); i >= 0 {
+		name, arg = t[:i], t[i+1:]
+	}
+
+	for _, c := range commands {
+		if c.name == name {
+			return c, strings.TrimSpace(arg), true
+		}
+	}
+	return nil, "", false
+}
+
+func (s *session) runCommand(t string) {
+	c, arg, ok := splitCommand(t)
+	if !ok {
+		fmt.Fprintf(s.out, "unknown command '%s', see :help\n", strings.Fields(t)[0])
+		return
+	}
+
+	if c.args != "" && arg == "" {
+		fmt.Fprintf(s.out, "usage: %s %s\n", c.name, c.args)
+		return
+	}
+	c.run(s, arg)
+}
+
+func (s *session) load(path string) {
+	// Syntax and runtime errors have already been reported by the runner.
+	var pathErr *fs.PathError
+	if _, err := runner.RunFile(path, s.env, s.out); errors.As(err, &pathErr) {
+		fmt.Fprintln(s.out, "error:", err)
+	}
+}
+
+func (s *session) listEnv(string) {
+	for _, name := range s.env.Names() {
+		val, _ := s.env.Get(name)
+		fmt.Fprintf(s.out, "%s = %s\n", name, summarize(val))
+	}
+}
+
+// summarize returns a one-line, length-limited rendering of obj.
+func summarize(obj object.Object) string {
+	const maxLen = 60
+
+	str := strings.Join(strings.Fields(obj.Inspect()), " ")
+	if r := []rune(str); len(r) > maxLen {
+		str = string(r[:maxLen-3]) + "..."
+	}
+	return str
+}
+
+func (s *session) dumpTokens(code string) {
+	l := lexer.NewLexer(code)
+	for tok := l.NextToken(); ; tok = l.NextToken() {
+		fmt.Fprintf(s.out, "%-8s %-10s %q\n", tok.Pos, tok.Type, tok.Literal)
+		if tok.Type == token.EOF {
+			break
+		}
+	}
+}
+
+func (s *session) dumpAST(code string) {
+	if program, ok := s.parse(code); ok {
+		fmt.Fprint(s.out, ast.Dump(program))
+	}
+}
+
+func (s *session) reset(string) {
+	s.env = object.NewEnvironment()
+}
+
+func (s *session) time(code string) {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+
+	s.eval(code)
+
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	fmt.Fprintf(s.out, "time: %s, allocations: %d (%d bytes)\n",
+		elapsed, after.Mallocs-before.Mallocs, after.TotalAlloc-before.TotalAlloc)
+}
+
+func (s *session) help(string) {
+	for _, c := range commands {
+		fmt.Fprintf(s.out, "  %-16s %s\n", strings.TrimSpace(c.name+" "+c.args), c.help)
+	}
+}
@@ -0,0 +1,67 @@
+package repl
+
+import (
+	"go-interpreter/evaluator"
+	"go-interpreter/token"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// completer returns the candidates for completing line, the input left of the
+// cursor, together with the index of the rune the completed word starts at.
+type completer func(line string) (int, []string)
+
+// complete completes identifiers against the keywords, the builtins and every
+// name visible from the current environment, and REPL commands at the start
+// of the input.
+func (s *session) complete(line string) (int, []string) {
+	rs := []rune(line)
+	start := len(rs)
+	for start > 0 && isWordRune(rs[start-1]) {
+		start--
+	}
+
+	var names []string
+	if start == 1 && rs[0] == ':' {
+		start = 0
+		for _, c := range commands {
+			names = append(names, c.name)
+		}
+	} else {
+		names = append(names, token.Keywords()...)
+		names = append(names, evaluator.BuiltinNames()...)
+		names = append(names, s.env.AllNames()...)
+	}
+
+	word := string(rs[start:])
+	seen := map[string]bool{}
+	candidates := []string{}
+	for _, name := range names {
+		if strings.HasPrefix(name, word) && !seen[name] {
+			seen[name] = true
+			candidates = append(candidates, name)
+		}
+	}
+	sort.Strings(candidates)
+	return start, candidates
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func commonPrefix(strs []string) string {
+	if len(strs) == 0 {
+		return ""
+	}
+	prefix := strs[0]
+	for _, s := range strs[1:] {
+		for !strings.HasPrefix(s, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
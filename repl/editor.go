@@ -0,0 +1,437 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var errInterrupted = errors.New("interrupted")
+
+type lineReader interface {
+	ReadLine(prompt string) (string, error)
+	AddHistory(entry string) // records a complete, possibly multi-line, input
+	Close() error
+}
+
+func newLineReader(in io.Reader, out io.Writer, complete completer) lineReader {
+	if f, ok := in.(*os.File); ok && isTerminal(int(f.Fd())) {
+		return newEditor(f, out, loadHistory(historyPath()), complete)
+	}
+	return &plainReader{scanner: bufio.NewScanner(in), out: out}
+}
+
+// plainReader reads lines without any editing, e.g. when input is piped in.
+type plainReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func (r *plainReader) ReadLine(prompt string) (string, error) {
+	fmt.Fprint(r.out, prompt)
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return r.scanner.Text(), nil
+}
+
+func (r *plainReader) AddHistory(entry string) {}
+func (r *plainReader) Close() error            { return nil }
+
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyCtrlG     = 7
+	keyCtrlH     = 8
+	keyTab       = 9
+	keyCtrlJ     = 10
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyEnter     = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlR     = 18
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEsc       = 27
+	keyBackspace = 127
+
+	// Pseudo keys decoded from escape sequences.
+	keyUp rune = unicode.MaxRune + 1 + iota
+	keyDown
+	keyRight
+	keyLeft
+	keyHome
+	keyEnd
+	keyDelete
+	keyUnknown
+)
+
+// editor is a minimal readline replacement working on a terminal in raw mode:
+// cursor movement, history navigation, reverse search and tab completion.
+type editor struct {
+	f        *os.File // the terminal, nil when not switching modes
+	in       *bufio.Reader
+	out      io.Writer
+	history  *history
+	complete completer
+
+	prompt  string
+	buf     []rune
+	pos     int
+	histIdx int
+	pending []rune // the edited line while browsing history
+	row     int    // the line of buf the cursor is on
+}
+
+func newEditor(f *os.File, out io.Writer, h *history, complete completer) *editor {
+	return &editor{f: f, in: bufio.NewReader(f), out: out, history: h, complete: complete}
+}
+
+func (e *editor) ReadLine(prompt string) (string, error) {
+	if e.f != nil {
+		restore, err := makeRaw(int(e.f.Fd()))
+		if err != nil {
+			return "", err
+		}
+		defer restore()
+	}
+
+	return e.readLine(prompt)
+}
+
+func (e *editor) AddHistory(entry string) { e.history.Add(entry) }
+func (e *editor) Close() error            { return e.history.Close() }
+
+func (e *editor) readLine(prompt string) (string, error) {
+	e.prompt, e.buf, e.pos, e.row = prompt, nil, 0, 0
+	e.histIdx, e.pending = len(e.history.entries), nil
+	e.refresh()
+
+	for {
+		key, err := e.readKey()
+		if err != nil {
+			return "", err
+		}
+
+		switch key {
+		case keyEnter, keyCtrlJ:
+			e.pos = len(e.buf)
+			e.refresh()
+			io.WriteString(e.out, "\r\n")
+			return string(e.buf), nil
+
+		case keyCtrlC:
+			io.WriteString(e.out, "^C\r\n")
+			return "", errInterrupted
+
+		case keyCtrlD:
+			if len(e.buf) == 0 {
+				io.WriteString(e.out, "\r\n")
+				return "", io.EOF
+			}
+			e.delete()
+
+		case keyCtrlR:
+			submit, err := e.reverseSearch()
+			if err != nil {
+				return "", err
+			}
+			if submit {
+				e.refresh()
+				io.WriteString(e.out, "\r\n")
+				return string(e.buf), nil
+			}
+
+		case keyTab:
+			e.completeWord()
+		case keyCtrlA, keyHome:
+			e.pos = 0
+		case keyCtrlE, keyEnd:
+			e.pos = len(e.buf)
+		case keyCtrlB, keyLeft:
+			if e.pos > 0 {
+				e.pos--
+			}
+		case keyCtrlF, keyRight:
+			if e.pos < len(e.buf) {
+				e.pos++
+			}
+		case keyBackspace, keyCtrlH:
+			if e.pos > 0 {
+				e.pos--
+				e.delete()
+			}
+		case keyDelete:
+			e.delete()
+		case keyCtrlK:
+			e.buf = e.buf[:e.pos]
+		case keyCtrlU:
+			e.buf = append([]rune(nil), e.buf[e.pos:]...)
+			e.pos = 0
+		case keyCtrlW:
+			e.deleteWord()
+		case keyCtrlP, keyUp:
+			e.browseHistory(-1)
+		case keyCtrlN, keyDown:
+			e.browseHistory(1)
+		case keyCtrlL:
+			io.WriteString(e.out, "\x1b[H\x1b[2J")
+
+		default:
+			if key >= ' ' && key <= unicode.MaxRune {
+				e.insert([]rune{key})
+			}
+		}
+
+		e.refresh()
+	}
+}
+
+// readKey reads one key press, decoding the escape sequences of the cursor
+// and editing keys.
+func (e *editor) readKey() (rune, error) {
+	r, _, err := e.in.ReadRune()
+	if err != nil || r != keyEsc {
+		return r, err
+	}
+
+	r, _, err = e.in.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	if r != '[' && r != 'O' {
+		return keyUnknown, nil
+	}
+
+	var param []rune
+	for {
+		r, _, err = e.in.ReadRune()
+		if err != nil {
+			return 0, err
+		}
+		if r < '0' || r > '9' && r != ';' {
+			break
+		}
+		param = append(param, r)
+	}
+
+	switch r {
+	case 'A':
+		return keyUp, nil
+	case 'B':
+		return keyDown, nil
+	case 'C':
+		return keyRight, nil
+	case 'D':
+		return keyLeft, nil
+	case 'H':
+		return keyHome, nil
+	case 'F':
+		return keyEnd, nil
+	case '~':
+		switch string(param) {
+		case "1", "7":
+			return keyHome, nil
+		case "4", "8":
+			return keyEnd, nil
+		case "3":
+			return keyDelete, nil
+		}
+	}
+	return keyUnknown, nil
+}
+
+// refresh redraws the buffer. The lines of a multi-line history entry after
+// the first are shown after CONTINUATION_PROMPT.
+func (e *editor) refresh() {
+	var b strings.Builder
+	if e.row > 0 {
+		fmt.Fprintf(&b, "\x1b[%dA", e.row)
+	}
+	b.WriteString("\r\x1b[J")
+
+	lines := strings.Split(string(e.buf), "\n")
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString("\r\n" + CONTINUATION_PROMPT)
+		} else {
+			b.WriteString(e.prompt)
+		}
+		b.WriteString(line)
+	}
+
+	before := string(e.buf[:e.pos])
+	e.row = strings.Count(before, "\n")
+	if up := len(lines) - 1 - e.row; up > 0 {
+		fmt.Fprintf(&b, "\x1b[%dA", up)
+	}
+	prompt := e.prompt
+	if e.row > 0 {
+		prompt = CONTINUATION_PROMPT
+	}
+	col := utf8.RuneCountInString(prompt + before[strings.LastIndex(before, "\n")+1:])
+	b.WriteString("\r")
+	if col > 0 {
+		fmt.Fprintf(&b, "\x1b[%dC", col)
+	}
+	io.WriteString(e.out, b.String())
+}
+
+func (e *editor) insert(rs []rune) {
+	buf := append([]rune(nil), e.buf[:e.pos]...)
+	buf = append(buf, rs...)
+	e.buf = append(buf, e.buf[e.pos:]...)
+	e.pos += len(rs)
+}
+
+func (e *editor) delete() {
+	if e.pos < len(e.buf) {
+		e.buf = append(e.buf[:e.pos], e.buf[e.pos+1:]...)
+	}
+}
+
+// completeWord completes the word left of the cursor: a unique candidate is
+// filled in, otherwise their longest common prefix, and when that gets no
+// further the candidates are listed. Without a word to complete Tab indents.
+func (e *editor) completeWord() {
+	if e.complete == nil {
+		e.insert([]rune("    "))
+		return
+	}
+
+	start, candidates := e.complete(string(e.buf[:e.pos]))
+	word := string(e.buf[start:e.pos])
+	if word == "" {
+		e.insert([]rune("    "))
+		return
+	}
+
+	switch prefix := commonPrefix(candidates); {
+	case len(candidates) == 0:
+		io.WriteString(e.out, "\a")
+	case len(prefix) > len(word):
+		e.insert([]rune(prefix[len(word):]))
+	case len(candidates) > 1:
+		fmt.Fprintf(e.out, "\r\n%s\r\n", strings.Join(candidates, "  "))
+	}
+}
+
+func (e *editor) deleteWord() {
+	i := e.pos
+	for i > 0 && unicode.IsSpace(e.buf[i-1]) {
+		i--
+	}
+	for i > 0 && !unicode.IsSpace(e.buf[i-1]) {
+		i--
+	}
+	e.buf = append(e.buf[:i], e.buf[e.pos:]...)
+	e.pos = i
+}
+
+// browseHistory moves dir entries through the history, keeping the line being
+// edited so that moving past the newest entry brings it back.
+func (e *editor) browseHistory(dir int) {
+	idx := e.histIdx + dir
+	if idx < 0 || idx > len(e.history.entries) {
+		return
+	}
+
+	if e.histIdx == len(e.history.entries) {
+		e.pending = e.buf
+	}
+	e.histIdx = idx
+
+	if idx == len(e.history.entries) {
+		e.buf = e.pending
+	} else {
+		e.buf = []rune(e.history.entries[idx])
+	}
+	e.pos = len(e.buf)
+}
+
+// reverseSearch runs an incremental search backwards through the history.
+// Enter submits the match, other editing keys accept it into the line, and
+// Ctrl-G or Ctrl-C restore the line as it was. While no entry contains the
+// query the search is failing, and ending it keeps the line as it was.
+func (e *editor) reverseSearch() (bool, error) {
+	var query []rune
+	match := len(e.history.entries) - 1
+	failing := false
+
+	if e.row > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dA", e.row)
+		e.row = 0
+	}
+
+	for {
+		line := ""
+		if match >= 0 {
+			line = strings.ReplaceAll(e.history.entries[match], "\n", " ")
+		}
+		status := "reverse-i-search"
+		if failing {
+			status = "failing " + status
+		}
+		fmt.Fprintf(e.out, "\r\x1b[J(%s)`%s': %s", status, string(query), line)
+
+		key, err := e.readKey()
+		if err != nil {
+			return false, err
+		}
+
+		switch key {
+		case keyCtrlR:
+			if match > 0 {
+				if m := e.history.search(string(query), match-1); m >= 0 {
+					match = m
+				}
+			}
+			continue
+
+		case keyBackspace, keyCtrlH:
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				m := e.history.search(string(query), len(e.history.entries)-1)
+				if failing = m < 0; !failing {
+					match = m
+				}
+			}
+			continue
+
+		case keyCtrlG, keyCtrlC:
+			return false, nil
+
+		default:
+			if key >= ' ' && key <= unicode.MaxRune {
+				query = append(query, key)
+				m := e.history.search(string(query), match)
+				if failing = m < 0; !failing {
+					match = m
+				}
+				continue
+			}
+		}
+
+		if failing {
+			return false, nil
+		}
+		if match >= 0 {
+			e.buf = []rune(e.history.entries[match])
+			e.pos = len(e.buf)
+		}
+		return key == keyEnter || key == keyCtrlJ, nil
+	}
+}
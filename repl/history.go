@@ -0,0 +1,119 @@
+package repl
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	HISTORY_FILE = ".yl_history"
+	HISTORY_SIZE = 1000
+)
+
+type history struct {
+	entries []string
+	file    *os.File // nil when history is not persisted
+}
+
+// historyPath returns $YL_HISTORY if it is set, else ~/.yl_history.
+func historyPath() string {
+	if path, ok := os.LookupEnv("YL_HISTORY"); ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, HISTORY_FILE)
+}
+
+// loadHistory reads the history stored at path and keeps the file open for
+// appending new entries. An empty path gives an in-memory history.
+func loadHistory(path string) *history {
+	h := &history{}
+	if path == "" {
+		return h
+	}
+
+	if f, err := os.Open(path); err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			h.entries = append(h.entries, decodeEntry(scanner.Text()))
+		}
+		f.Close()
+	}
+
+	if len(h.entries) > HISTORY_SIZE {
+		h.entries = h.entries[len(h.entries)-HISTORY_SIZE:]
+		var b strings.Builder
+		for _, entry := range h.entries {
+			b.WriteString(encodeEntry(entry) + "\n")
+		}
+		os.WriteFile(path, []byte(b.String()), 0600)
+	}
+
+	h.file, _ = os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	return h
+}
+
+func (h *history) Add(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if n := len(h.entries); n > 0 && h.entries[n-1] == line {
+		return
+	}
+
+	h.entries = append(h.entries, line)
+	if len(h.entries) > HISTORY_SIZE {
+		h.entries = h.entries[1:]
+	}
+
+	if h.file != nil {
+		h.file.WriteString(encodeEntry(line) + "\n")
+	}
+}
+
+// entryEscaper escapes the newlines of multi-line entries, and so
+// backslashes, to store each entry on one line of the history file.
+var entryEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func encodeEntry(entry string) string { return entryEscaper.Replace(entry) }
+
+func decodeEntry(line string) string {
+	var b strings.Builder
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' && i+1 < len(line) {
+			i++
+			if line[i] == 'n' {
+				b.WriteByte('\n')
+				continue
+			}
+		}
+		b.WriteByte(line[i])
+	}
+	return b.String()
+}
+
+// search returns the index of the newest entry at or before from that
+// contains query, or -1.
+func (h *history) search(query string, from int) int {
+	if from >= len(h.entries) {
+		from = len(h.entries) - 1
+	}
+	for i := from; i >= 0; i-- {
+		if strings.Contains(h.entries[i], query) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (h *history) Close() error {
+	if h.file != nil {
+		return h.file.Close()
+	}
+	return nil
+}
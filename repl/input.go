@@ -0,0 +1,109 @@
+package repl
+
+import (
+	"go-interpreter/lexer"
+	"go-interpreter/token"
+	"io"
+	"strings"
+)
+
+// continuationTokens cannot end a statement, so input ending with one of them
+// goes on in the next line.
+var continuationTokens = map[token.TokenType]bool{
+	token.ASSIGN:             true,
+	token.BANG:               true,
+	token.PLUS:               true,
+	token.PLUS_ASSIGN:        true,
+	token.MINUS:              true,
+	token.MINUS_ASSIGN:       true,
+	token.ASTERISK:           true,
+	token.ASTERISK_ASSIGN:    true,
+	token.SLASH:              true,
+	token.SLASH_ASSIGN:       true,
+	token.MOD:                true,
+	token.MOD_ASSIGN:         true,
+	token.LT:                 true,
+	token.GT:                 true,
+	token.LE:                 true,
+	token.GE:                 true,
+	token.EQ:                 true,
+	token.NOT_EQ:             true,
+	token.LOGICAL_AND:        true,
+	token.LOGICAL_OR:         true,
+	token.BITWISE_AND:        true,
+	token.BITWISE_AND_ASSIGN: true,
+	token.BITWISE_OR:         true,
+	token.BITWISE_OR_ASSIGN:  true,
+	token.BITWISE_XOR:        true,
+	token.BITWISE_XOR_ASSIGN: true,
+	token.BITWISE_NOT:        true,
+	token.SHL:                true,
+	token.SHL_ASSIGN:         true,
+	token.SHR:                true,
+	token.SHR_ASSIGN:         true,
+	token.COMMA:              true,
+	token.COLON:              true,
+}
+
+// isIncomplete reports whether src needs more lines to form a complete input:
+// it has unclosed brackets, an unterminated string or a trailing operator.
+func isIncomplete(src string) bool {
+	l := lexer.NewLexer(src)
+	depth := 0
+	last := &token.Token{}
+
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		switch tok.Type {
+		case token.LPAREN, token.LBRACE, token.LBRACKET:
+			depth++
+		case token.RPAREN, token.RBRACE, token.RBRACKET:
+			depth--
+		}
+		last = tok
+	}
+
+	for _, err := range l.Errors() {
+		if strings.HasPrefix(err.(*token.Error).Msg, "unterminated") {
+			return true
+		}
+	}
+
+	return depth > 0 || continuationTokens[last.Type]
+}
+
+// needsMoreInput reports whether src is incomplete, looking only at the code
+// argument of REPL commands.
+func needsMoreInput(src string) bool {
+	if strings.HasPrefix(src, ":") {
+		c, arg, ok := splitCommand(src)
+		return ok && c.takesCode && isIncomplete(arg)
+	}
+	return isIncomplete(src)
+}
+
+// readInput reads lines until they form a complete input, prompting with
+// CONTINUATION_PROMPT for all but the first one.
+func readInput(r lineReader) (string, error) {
+	var lines []string
+	prompt := PROMPT
+
+	for {
+		line, err := r.ReadLine(prompt)
+		if err != nil {
+			if err == io.EOF && len(lines) > 0 {
+				src := strings.Join(lines, "\n")
+				r.AddHistory(src)
+				return src, nil
+			}
+			return "", err
+		}
+
+		lines = append(lines, line)
+		src := strings.Join(lines, "\n")
+		if !needsMoreInput(src) {
+			r.AddHistory(src)
+			return src, nil
+		}
+		prompt = CONTINUATION_PROMPT
+	}
+}
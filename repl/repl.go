@@ -1,53 +1,91 @@
 package repl
 
 import (
-	"bufio"
 	"fmt"
+	"go-interpreter/ast"
+	"go-interpreter/diagnostics"
 	"go-interpreter/evaluator"
 	"go-interpreter/lexer"
 	"go-interpreter/object"
 	"go-interpreter/parser"
+	"go-interpreter/token"
 	"io"
+	"strings"
 )
 
-const PROMPT = ">>> "
+const (
+	PROMPT              = ">>> "
+	CONTINUATION_PROMPT = "... "
+	FILENAME            = "<stdin>"
+)
+
+type session struct {
+	out  io.Writer
+	env  *object.Environment
+	diag *diagnostics.Printer
+	pos  token.Position // where the next input starts in FILENAME
+}
 
 func Start(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
-	env := object.NewEnvironment()
+	s := &session{
+		out:  out,
+		env:  object.NewEnvironment(),
+		diag: diagnostics.NewPrinter(out),
+		pos:  token.Position{Filename: FILENAME, Line: 1, Column: 1},
+	}
+
+	r := newLineReader(in, out, s.complete)
+	defer r.Close()
 
 	for {
-		fmt.Fprint(out, PROMPT)
-		if !scanner.Scan() {
+		t, err := readInput(r)
+		if err == errInterrupted {
+			continue
+		}
+		if err != nil {
 			break
 		}
 
-		t := scanner.Text()
-
-		p := parser.NewParser(lexer.NewLexer(t))
-		program := p.ParseProgram()
-		if len(p.Errors()) != 0 {
-			printParserErrors(out, p.Errors())
+		if strings.HasPrefix(t, ":") {
+			s.runCommand(t)
 			continue
 		}
 
-		// fmt.Fprintln(out, program.String())
-		res, err := evaluator.Eval(program, env)
-		if err != nil {
-			fmt.Fprintln(out, "Error:", err)
-			continue
-		}
+		s.eval(t)
+	}
+}
 
-		if res != nil && res.Type() != object.EXPLIST_OBJ {
-			fmt.Fprintln(out, res.Inspect())
-		}
+// parse parses t as the next input. Every input continues the same virtual
+// file, so errors raised later by functions defined in earlier inputs still
+// point at their source.
+func (s *session) parse(t string) (*ast.Program, bool) {
+	s.diag.AppendSource(FILENAME, t)
+	p := parser.NewParser(lexer.NewLexerAt(s.pos, t))
+	s.pos.Line += strings.Count(t, "\n") + 1
+	s.pos.Offset += len(t) + 1
+
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		s.diag.PrintErrors(p.Errors())
+		return nil, false
 	}
+	return program, true
 }
 
-func printParserErrors(out io.Writer, errors []error) {
-	fmt.Fprintln(out, "parser errors:")
+func (s *session) eval(t string) {
+	program, ok := s.parse(t)
+	if !ok {
+		return
+	}
+
+	// fmt.Fprintln(s.out, program.String())
+	res, err := evaluator.Eval(program, s.env)
+	if err != nil {
+		s.diag.PrintError(err)
+		return
+	}
 
-	for _, err := range errors {
-		fmt.Fprintln(out, err)
+	if res != nil && res.Type() != object.EXPLIST_OBJ {
+		fmt.Fprintln(s.out, res.Inspect())
 	}
 }
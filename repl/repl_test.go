@@ -0,0 +1,247 @@
+package repl
+
+import (
+	"bufio"
+	"go-interpreter/object"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsIncomplete(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"1 + 2", false},
+		{"let f = fn(x) {", true},
+		{"let f = fn(x) {\n  x\n}", false},
+		{"add(1,", true},
+		{"[1, 2", true},
+		{"1 +", true},
+		{"let a =", true},
+		{"a && b ||", true},
+		{`"abc`, true},
+		{`"`, true},
+		{`"abc"`, false},
+		{`"a\"`, true},
+		{`"a\\"`, false},
+		{"`raw", true},
+		{"`raw\nstring`", false},
+		{`"a\q"`, false},
+		{"/* comment", true},
+		{"/* comment */ 1", false},
+		{"1 + // comment", true},
+		{"}", false},
+		{"++i", false},
+	}
+
+	for _, tt := range tests {
+		if res := isIncomplete(tt.input); res != tt.expected {
+			t.Errorf("isIncomplete(%q) wrong. expected=%t, got=%t", tt.input, tt.expected, res)
+		}
+	}
+}
+
+func TestReadInput(t *testing.T) {
+	r := &plainReader{
+		scanner: bufio.NewScanner(strings.NewReader("let f = fn(x) {\n  x +\n  1\n}\nf(1)\n[1,\n")),
+		out:     io.Discard,
+	}
+
+	expected := []string{"let f = fn(x) {\n  x +\n  1\n}", "f(1)", "[1,"}
+	for _, e := range expected {
+		src, err := readInput(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if src != e {
+			t.Errorf("wrong input. expected=%q, got=%q", e, src)
+		}
+	}
+
+	if _, err := readInput(r); err != io.EOF {
+		t.Errorf("expected EOF, got %v", err)
+	}
+}
+
+func TestEditor(t *testing.T) {
+	h := &history{entries: []string{"let a = 1", "a + 2", "print(a)"}}
+
+	tests := []struct {
+		keys     string
+		expected string
+		err      error
+	}{
+		{"abc\r", "abc", nil},
+		{"abd\x7fc\r", "abc", nil},
+		{"bc\x01a\r", "abc", nil},
+		{"ac\x1b[Db\r", "abc", nil},
+		{"abcdef\x1b[D\x1b[D\x1b[D\x0b\r", "abc", nil},
+		{"xyz\x02\x02\x1b[3~\r", "xz", nil},
+		{"let abc\x17x\r", "let x", nil},
+		{"\x1b[A\x1b[A\r", "a + 2", nil},
+		{"q\x1b[A\x1b[B\r", "q", nil},
+		{"\x10\x10\x10\x10\x0e\r", "a + 2", nil},
+		{"\x12a\r", "print(a)", nil},
+		{"\x12a\x12\r", "a + 2", nil},
+		{"\x12let\x05;\r", "let a = 1;", nil},
+		{"x\x12zz\x07\r", "x", nil},
+		{"x\x12pz\r\r", "x", nil},
+		{"\x12pz\x05\r", "", nil},
+		{"\x12pz\x7f\r", "print(a)", nil},
+		{"\x12a +x\x7f\x7f\x12\r", "let a = 1", nil},
+		{"abc\x03", "", errInterrupted},
+		{"\x04", "", io.EOF},
+	}
+
+	for _, tt := range tests {
+		e := &editor{in: bufio.NewReader(strings.NewReader(tt.keys)), out: io.Discard, history: h}
+		line, err := e.readLine(PROMPT)
+		if err != tt.err {
+			t.Errorf("keys %q: wrong error. expected=%v, got=%v", tt.keys, tt.err, err)
+			continue
+		}
+		if line != tt.expected {
+			t.Errorf("keys %q: wrong line. expected=%q, got=%q", tt.keys, tt.expected, line)
+		}
+	}
+}
+
+func TestMultiLineHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	h := loadHistory(path)
+
+	e := &editor{in: bufio.NewReader(strings.NewReader("let f = fn() {\r`a\\b`\r}\r")), out: io.Discard, history: h}
+	src, err := readInput(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "let f = fn() {\n`a\\b`\n}"
+	if src != expected {
+		t.Fatalf("wrong input. expected=%q, got=%q", expected, src)
+	}
+	if len(h.entries) != 1 || h.entries[0] != expected {
+		t.Errorf("wrong history. expected=%q, got=%q", []string{expected}, h.entries)
+	}
+
+	e = &editor{in: bufio.NewReader(strings.NewReader("\x1b[A\r")), out: io.Discard, history: h}
+	if line, _ := e.readLine(PROMPT); line != expected {
+		t.Errorf("wrong recalled entry. expected=%q, got=%q", expected, line)
+	}
+
+	h.Close()
+	h = loadHistory(path)
+	defer h.Close()
+	if len(h.entries) != 1 || h.entries[0] != expected {
+		t.Errorf("wrong reloaded history. expected=%q, got=%q", []string{expected}, h.entries)
+	}
+}
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let a, b = 1, [2]\n:env", []string{"a = 1\n", "b = [2]\n"}},
+		{"let a = 1\n:reset\n:env\na", []string{"<stdin>:2:1: error: name 'a' is not defined\n"}},
+		{":tokens let a", []string{"1:1      LET        \"let\"\n", "1:5      IDENT      \"a\"\n", "1:6      EOF        \"\"\n"}},
+		{":ast -x", []string{"*ast.Program (1:1-1:3)\n  Stmts: [\n    0: *ast.ExpressionStatement (1:1-1:3)\n      Expr: *ast.PrefixExpression (1:1-1:3)\n        Operator: \"-\"\n        Right: *ast.Identifier (1:2-1:3)\n          Value: \"x\"\n  ]\n"}},
+		{":time [1,\n 2]", []string{"[1, 2]\ntime: "}},
+		{":load", []string{"usage: :load <file>\n"}},
+		{":nope", []string{"unknown command ':nope', see :help\n"}},
+		{":help", []string{"  :env             list the bindings of the current environment\n"}},
+	}
+
+	for _, tt := range tests {
+		var out strings.Builder
+		Start(strings.NewReader(tt.input), &out)
+
+		for _, e := range tt.expected {
+			if !strings.Contains(out.String(), e) {
+				t.Errorf("input %q: output does not contain %q. got=%q", tt.input, e, out.String())
+			}
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	s := &session{env: object.NewEnvironment()}
+	s.env.Set("result", object.NULL)
+	s.env.Set("revenue", object.NULL)
+	inner := object.NewEnclosedEnvironment(s.env)
+	inner.Set("local", object.NULL)
+	s.env = inner
+
+	tests := []struct {
+		line          string
+		expectedStart int
+		expected      []string
+	}{
+		{"re", 0, []string{"result", "return", "revenue", "reverse"}},
+		{"let x = su", 8, []string{"sum"}},
+		{"f(lo", 2, []string{"local"}},
+		{"le", 0, []string{"len", "let"}},
+		{"xyz", 0, []string{}},
+		{":e", 0, []string{":env"}},
+		{"a + :t", 5, []string{"true"}},
+	}
+
+	for _, tt := range tests {
+		start, candidates := s.complete(tt.line)
+		if start != tt.expectedStart {
+			t.Errorf("complete(%q) wrong start. expected=%d, got=%d", tt.line, tt.expectedStart, start)
+		}
+		if strings.Join(candidates, " ") != strings.Join(tt.expected, " ") {
+			t.Errorf("complete(%q) wrong candidates. expected=%v, got=%v", tt.line, tt.expected, candidates)
+		}
+	}
+}
+
+func TestCommonPrefix(t *testing.T) {
+	tests := []struct {
+		strs     []string
+		expected string
+	}{
+		{nil, ""},
+		{[]string{"reverse", "return"}, "re"},
+		{[]string{"é", "è"}, ""},
+		{[]string{"xé1", "xé2", "xè"}, "x"},
+		{[]string{"naïve", "naïf"}, "naï"},
+	}
+
+	for _, tt := range tests {
+		if res := commonPrefix(tt.strs); res != tt.expected {
+			t.Errorf("commonPrefix(%q) wrong. expected=%q, got=%q", tt.strs, tt.expected, res)
+		}
+	}
+}
+
+func TestEditorCompletion(t *testing.T) {
+	s := &session{env: object.NewEnvironment()}
+	s.env.Set("counter", object.NULL)
+
+	tests := []struct {
+		keys     string
+		expected string
+	}{
+		{"cou\t\r", "counter"},
+		{"pri\t(1)\r", "print(1)"},
+		{"re\t\t\r", "re"},
+		{"rev\t\r", "reverse"},
+		{"\tx\r", "    x"},
+		{"zz\t\r", "zz"},
+	}
+
+	for _, tt := range tests {
+		e := &editor{in: bufio.NewReader(strings.NewReader(tt.keys)), out: io.Discard, history: &history{}, complete: s.complete}
+		line, err := e.readLine(PROMPT)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line != tt.expected {
+			t.Errorf("keys %q: wrong line. expected=%q, got=%q", tt.keys, tt.expected, line)
+		}
+	}
+}
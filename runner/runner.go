@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"errors"
+	"go-interpreter/diagnostics"
+	"go-interpreter/evaluator"
+	"go-interpreter/lexer"
+	"go-interpreter/object"
+	"go-interpreter/parser"
+	"io"
+	"os"
+)
+
+// ErrSyntax is returned by Run when the program could not be parsed.
+var ErrSyntax = errors.New("syntax error")
+
+// NewEnvironment returns a global environment with the script arguments bound
+// to 'args'.
+func NewEnvironment(args []string) *object.Environment {
+	elements := []object.Object{}
+	for _, a := range args {
+		elements = append(elements, object.NewString(a))
+	}
+
+	env := object.NewEnvironment()
+	env.Set("args", object.NewArray(elements))
+	return env
+}
+
+// Run parses and evaluates src as the file filename in env. Syntax and
+// runtime errors are rendered to errOut and returned.
+func Run(filename, src string, env *object.Environment, errOut io.Writer) (object.Object, error) {
+	p := parser.NewParser(lexer.NewFileLexer(filename, src))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		diagnostics.Fprint(errOut, filename, src, p.Errors()...)
+		return nil, ErrSyntax
+	}
+
+	res, err := evaluator.Eval(program, env)
+	if err != nil {
+		diagnostics.Fprint(errOut, filename, src, err)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// RunFile reads and runs the script at path.
+func RunFile(path string, env *object.Environment, errOut io.Writer) (object.Object, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Run(path, string(src), env, errOut)
+}
@@ -0,0 +1,59 @@
+package runner
+
+import (
+	"go-interpreter/object"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		src            string
+		args           []string
+		expected       string
+		expectedErrOut string
+	}{
+		{"#!/usr/bin/env yl\nlen(args)", []string{"a", "b"}, "2", ""},
+		{"args", []string{"a"}, `["a"]`, ""},
+		{"let a = 1\nlet b = (", nil, "", "main.yl:2:10: error: no prefix parse function for 'EOF' found\n" +
+			" 2 | let b = (\n" +
+			"   |          ^\n"},
+		{"let a = 1\na + c", nil, "", "main.yl:2:5: error: name 'c' is not defined\n" +
+			" 2 | a + c\n" +
+			"   |     ^\n"},
+		{"exit(256)", nil, "", "main.yl:1:1: error: exit code must be between 0 and 255, got 256\n" +
+			" 1 | exit(256)\n" +
+			"   | ^^^^^^^^^\n"},
+		{"exit(-1)", nil, "", "main.yl:1:1: error: exit code must be between 0 and 255, got -1\n" +
+			" 1 | exit(-1)\n" +
+			"   | ^^^^^^^^\n"},
+		{"exit(1 << 64)", nil, "", "main.yl:1:1: error: exit code must be between 0 and 255, got 18446744073709551616\n" +
+			" 1 | exit(1 << 64)\n" +
+			"   | ^^^^^^^^^^^^^\n"},
+	}
+
+	for _, tt := range tests {
+		var errOut strings.Builder
+		res, err := Run("main.yl", tt.src, NewEnvironment(tt.args), &errOut)
+
+		if errOut.String() != tt.expectedErrOut {
+			t.Errorf("wrong error output. expected=%q, got=%q", tt.expectedErrOut, errOut.String())
+		}
+
+		if tt.expectedErrOut != "" {
+			if err == nil {
+				t.Errorf("expected an error for %q", tt.src)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			continue
+		}
+
+		if res == nil || res == object.NULL || res.Inspect() != tt.expected {
+			t.Errorf("wrong result. expected=%s, got=%v", tt.expected, res)
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package token
 
+import (
+	"fmt"
+	"sort"
+)
+
 const (
 	ILLEGAL = "ILLEGAL"
 	EOF     = "EOF"
@@ -8,6 +13,7 @@ const (
 	IDENT  = "IDENT"
 	STRING = "STRING"
 	INT    = "INT"
+	FLOAT  = "FLOAT"
 
 	// Operators
 	ASSIGN             = "="
@@ -54,6 +60,7 @@ const (
 	RBRACE    = "}"
 	LBRACKET  = "["
 	RBRACKET  = "]"
+	ELLIPSIS  = "..."
 
 	// Keywords
 	FUNCTION = "FUNCTION"
@@ -62,21 +69,49 @@ const (
 	FALSE    = "FALSE"
 	IF       = "IF"
 	ELSE     = "ELSE"
+	SWITCH   = "SWITCH"
+	CASE     = "CASE"
+	DEFAULT  = "DEFAULT"
+	MATCH    = "MATCH"
 	RETURN   = "RETURN"
 	FORLOOP  = "FOR"
+	WHILE    = "WHILE"
+	DO       = "DO"
+	BREAK    = "BREAK"
+	CONTINUE = "CONTINUE"
+	IN       = "IN"
 )
 
 type TokenType string
 
 var keywords = map[string]TokenType{
-	"fn":     FUNCTION,
-	"let":    LET,
-	"true":   TRUE,
-	"false":  FALSE,
-	"if":     IF,
-	"else":   ELSE,
-	"return": RETURN,
-	"for":    FORLOOP,
+	"fn":       FUNCTION,
+	"let":      LET,
+	"true":     TRUE,
+	"false":    FALSE,
+	"if":       IF,
+	"else":     ELSE,
+	"switch":   SWITCH,
+	"case":     CASE,
+	"default":  DEFAULT,
+	"match":    MATCH,
+	"return":   RETURN,
+	"for":      FORLOOP,
+	"while":    WHILE,
+	"do":       DO,
+	"break":    BREAK,
+	"continue": CONTINUE,
+	"in":       IN,
+}
+
+// Keywords returns all reserved words, sorted.
+func Keywords() []string {
+	res := make([]string, 0, len(keywords))
+	for k := range keywords {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
 }
 
 func LookupIdent(ident string) TokenType {
@@ -87,8 +122,16 @@ func LookupIdent(ident string) TokenType {
 }
 
 type Token struct {
-	Type    TokenType
-	Literal string
+	Type     TokenType
+	Literal  string
+	Pos, End Position  // End is the position immediately after the token
+	Comments []Comment // the comments between the previous token and this one
+}
+
+// Comment is a // line or /* block */ comment kept as trivia of a token.
+type Comment struct {
+	Text     string // including the comment markers
+	Pos, End Position
 }
 
 func NewToken(tp TokenType, s string) *Token {
@@ -97,3 +140,43 @@ func NewToken(tp TokenType, s string) *Token {
 		Literal: s,
 	}
 }
+
+type Position struct {
+	Filename string
+	Offset   int // byte offset, starting at 0
+	Line     int // line number, starting at 1
+	Column   int // column number, starting at 1 (rune count)
+}
+
+func (p Position) IsValid() bool { return p.Line > 0 }
+
+func (p Position) String() string {
+	s := p.Filename
+	if p.IsValid() {
+		if s != "" {
+			s += ":"
+		}
+		s += fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	if s == "" {
+		s = "-"
+	}
+	return s
+}
+
+// Error is an error annotated with the source span it refers to.
+type Error struct {
+	Pos, End Position
+	Msg      string
+}
+
+func NewError(pos, end Position, msg string) *Error {
+	return &Error{Pos: pos, End: end, Msg: msg}
+}
+
+func (e *Error) Error() string {
+	if e.Pos.IsValid() || e.Pos.Filename != "" {
+		return e.Pos.String() + ": " + e.Msg
+	}
+	return e.Msg
+}